@@ -0,0 +1,143 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+// master はシミュレーションに使用するガチャのマスターデータです。
+type master struct {
+	Characters []model.Character        `json:"characters"`
+	Items      []model.GachaProbability `json:"items"`
+	Pity       []model.PitySetting      `json:"pity"`
+	Guarantees []model.GachaGuarantee   `json:"guarantees"`
+	RateUps    []model.GachaRateUp      `json:"rate_ups"`
+	Steps      []model.GachaStep        `json:"steps"`
+}
+
+// loadFromDB はデータベースから指定されたガチャのマスターデータを読み込みます。
+func loadFromDB(dsn string, gachaID int64) (*master, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	repo := repository.NewGachaRepository(db)
+
+	m := &master{}
+	if m.Characters, err = repo.GetCharacters(); err != nil {
+		return nil, err
+	}
+	if m.Items, _, err = repo.GetGachaItems(gachaID); err != nil {
+		return nil, err
+	}
+	if m.Pity, err = repo.GetPitySettings(gachaID); err != nil {
+		return nil, err
+	}
+	if m.Guarantees, err = repo.GetGachaGuarantees(gachaID); err != nil {
+		return nil, err
+	}
+	if m.RateUps, err = repo.GetGachaRateUps(gachaID); err != nil {
+		return nil, err
+	}
+	if m.Steps, err = repo.GetGachaSteps(gachaID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// loadFromFile はファイルからマスターデータを読み込みます。拡張子が .csv の場合は CSV、それ以外は JSON として扱います。
+//
+// JSON はモデルの JSON 表現をそのまま使用します。
+//
+//	{"characters": [{"id": 1, "name": "Warrior", "rarity": 1}],
+//	 "items": [{"character_id": 1, "rarity": 1, "probability": 0.4}],
+//	 "pity": [{"rarity": 5, "hard_pity": 80, "soft_pity_start": 60, "soft_pity_step": 0.05}],
+//	 "guarantees": [{"draw_count": 10, "min_rarity": 3}],
+//	 "rate_ups": [{"rarity": 5, "share": 0.5, "guarantee": true, "character_ids": [5]}],
+//	 "steps": [{"step": 1, "times": 10, "cost": 500, "min_rarity": 0, "boost_rarity": 0, "boost_multiplier": 1}]}
+//
+// CSV は確率テーブルのみを表し、ヘッダー行 character_id,rarity,probability[,name] を持ちます。
+func loadFromFile(path string) (*master, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return loadCSV(f)
+	}
+
+	m := &master{}
+	if err := json.NewDecoder(f).Decode(m); err != nil {
+		return nil, err
+	}
+	// characters が省略された場合は、確率テーブルに含まれるキャラクターを補う
+	if len(m.Characters) == 0 {
+		for _, item := range m.Items {
+			m.Characters = append(m.Characters, model.Character{
+				ID:     item.CharacterID,
+				Name:   fmt.Sprintf("character %d", item.CharacterID),
+				Rarity: item.Rarity,
+			})
+		}
+	}
+	return m, nil
+}
+
+// loadCSV は CSV 形式の確率テーブルを読み込みます。
+func loadCSV(r io.Reader) (*master, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("csv has no header")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, name := range []string{"character_id", "rarity", "probability"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv is missing column %q", name)
+		}
+	}
+
+	m := &master{}
+	for line, record := range records[1:] {
+		id, err := strconv.ParseInt(strings.TrimSpace(record[columns["character_id"]]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid character_id: %v", line+2, err)
+		}
+		rarity, err := strconv.Atoi(strings.TrimSpace(record[columns["rarity"]]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rarity: %v", line+2, err)
+		}
+		probability, err := strconv.ParseFloat(strings.TrimSpace(record[columns["probability"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid probability: %v", line+2, err)
+		}
+		name := fmt.Sprintf("character %d", id)
+		if i, ok := columns["name"]; ok && i < len(record) {
+			name = strings.TrimSpace(record[i])
+		}
+
+		m.Items = append(m.Items, model.GachaProbability{CharacterID: id, Rarity: rarity, Probability: probability})
+		m.Characters = append(m.Characters, model.Character{ID: id, Name: name, Rarity: rarity})
+	}
+	return m, nil
+}
@@ -0,0 +1,268 @@
+// gachasim はガチャの確率テーブルを読み込み、サーバーと同じ抽選処理(天井・確定枠を含む)で
+// 大量の抽選をシミュレーションして、排出頻度や最高レアリティ獲得までの回数、設定確率への適合度を出力します。
+//
+// 使い方:
+//
+//	go run ./cmd/gachasim -dsn "user:password@tcp(localhost:3306)/dbname?parseTime=true" -gacha 1
+//	go run ./cmd/gachasim -file table.csv -draws 5000000 -times 10
+//	go run ./cmd/gachasim -dsn "..." -gacha 3 -step 5
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"my-go-project/internal/gacha"
+	"my-go-project/internal/model"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func main() {
+	dsn := flag.String("dsn", os.Getenv("DB_DSN"), "データベースの接続文字列 (-file を指定しない場合に使用)")
+	gachaID := flag.Int64("gacha", 1, "データベースから読み込むガチャID")
+	file := flag.String("file", "", "確率テーブルのファイル (.csv または .json)")
+	draws := flag.Int("draws", 1000000, "シミュレーションする総抽選回数")
+	times := flag.Int("times", 10, "1リクエストあたりの抽選回数 (確定枠の判定に使用)")
+	seed := flag.Int64("seed", 0, "乱数のシード (0 の場合はランダム)")
+	topRarity := flag.Int("top", 0, "獲得までの回数を集計するレアリティ (0 の場合はテーブル内の最高レアリティ)")
+	noPity := flag.Bool("no-pity", false, "天井を無効にする")
+	noGuarantee := flag.Bool("no-guarantee", false, "確定枠を無効にする")
+	noRateUp := flag.Bool("no-rate-up", false, "ピックアップ設定を無効にする")
+	compare := flag.Bool("compare", false, "エイリアス法と累積和の線形走査の抽選速度を比較する")
+	stepNo := flag.Int("step", 0, "ステップアップガチャの指定したステップを繰り返し引く (-times はステップの抽選回数で上書き)")
+	flag.Parse()
+
+	if *draws <= 0 || *times <= 0 {
+		log.Fatal("-draws and -times must be positive")
+	}
+
+	// マスターデータを読み込む
+	var m *master
+	var err error
+	if *file != "" {
+		m, err = loadFromFile(*file)
+	} else {
+		if *dsn == "" {
+			log.Fatal("either -file or -dsn (DB_DSN) is required")
+		}
+		m, err = loadFromDB(*dsn, *gachaID)
+	}
+	if err != nil {
+		log.Fatalf("Failed to load probability table: %v", err)
+	}
+
+	if *noPity {
+		m.Pity = nil
+	}
+	if *noGuarantee {
+		m.Guarantees = nil
+	}
+	if *noRateUp {
+		m.RateUps = nil
+	}
+
+	characters := make(map[int64]model.Character, len(m.Characters))
+	for _, c := range m.Characters {
+		characters[c.ID] = c
+	}
+
+	banner := gacha.NewBanner(m.Items, m.Pity, m.Guarantees, m.RateUps, m.Steps)
+	for _, problem := range banner.Validate(characters) {
+		fmt.Printf("WARNING: %s: %s\n", problem.Code, problem.Message)
+	}
+
+	// ステップが指定された場合は、ステップの排出率補正と確定枠で抽選する
+	var step *gacha.Step
+	if *stepNo > 0 {
+		s, ok := banner.Step(*stepNo)
+		if !ok {
+			log.Fatalf("step %d is not defined for this gacha", *stepNo)
+		}
+		step = &s
+		*times = s.Times
+	}
+
+	// 乱数生成器を生成
+	var source gacha.RNGSource = gacha.NewCryptoRNGSource()
+	if *seed != 0 {
+		source = gacha.NewSeededRNGSource(*seed)
+	}
+	rnd, usedSeed, err := source.New()
+	if err != nil {
+		log.Fatalf("Failed to create RNG: %v", err)
+	}
+
+	top := *topRarity
+	if top == 0 {
+		for _, item := range m.Items {
+			if item.Rarity > top {
+				top = item.Rarity
+			}
+		}
+	}
+
+	result, err := simulate(banner, step, rnd, *draws, *times, top)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("seed: %d, draws: %d, times per request: %d, pity: %v, guarantee: %v, step: %d\n\n",
+		usedSeed, *draws, *times, len(m.Pity) > 0, len(m.Guarantees) > 0, *stepNo)
+	table := banner.Table
+	if step != nil {
+		table = step.Table
+	}
+	printResult(result, banner, table, characters, *draws, top)
+
+	if *compare {
+		fmt.Println()
+		compareSamplers(banner, *draws)
+	}
+}
+
+// simulationResult はシミュレーションの集計結果です。
+type simulationResult struct {
+	counts       map[int64]int
+	rarityCounts map[int]int
+	intervals    []int
+}
+
+// simulate は times 回ずつのリクエストを合計 draws 回になるまで繰り返し、結果を集計します。
+// 天井カウンターとピックアップ確定の状態はリクエストをまたいで引き継がれ、1人のユーザーが引き続けた場合を再現します。
+// step が指定された場合は、各リクエストをそのステップとして抽選します(最後のリクエストも times 回引きます)。
+func simulate(banner *gacha.Banner, step *gacha.Step, rnd gacha.RNG, draws, times, top int) (*simulationResult, error) {
+	result := &simulationResult{
+		counts:       make(map[int64]int),
+		rarityCounts: make(map[int]int),
+	}
+	state := gacha.PityState{}
+	rateUpState := gacha.RateUpState{}
+
+	sinceTop := 0
+	for remaining := draws; remaining > 0; remaining -= times {
+		n := times
+		if remaining < n {
+			n = remaining
+		}
+		var pulls []gacha.Pull
+		var ok bool
+		if step != nil {
+			pulls, ok = banner.DrawStep(state, rateUpState, *step, rnd)
+		} else {
+			pulls, ok = banner.DrawBatch(state, rateUpState, n, rnd)
+		}
+		if !ok {
+			return nil, fmt.Errorf("draw failed: the probability table has nothing to draw")
+		}
+		for _, pull := range pulls {
+			result.counts[pull.Item.CharacterID]++
+			result.rarityCounts[pull.Item.Rarity]++
+			sinceTop++
+			if pull.Item.Rarity >= top {
+				result.intervals = append(result.intervals, sinceTop)
+				sinceTop = 0
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// printResult は集計結果を出力します。
+// 設定確率は table (ステップを指定した場合はステップの補正後のテーブル) から求めます。
+func printResult(result *simulationResult, banner *gacha.Banner, table *gacha.Table, characters map[int64]model.Character, draws, top int) {
+	rates := gacha.Rates(table.Items(), 0)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "characterID\tname\trarity\tconfigured\tobserved\tcount\t")
+	observed := make([]int, len(rates))
+	expected := make([]float64, len(rates))
+	for i, rate := range rates {
+		count := result.counts[rate.CharacterID]
+		observed[i] = count
+		expected[i] = rate.Rate
+		fmt.Fprintf(w, "%d\t%s\t%d\t%.5f\t%.5f\t%d\t\n",
+			rate.CharacterID, characters[rate.CharacterID].Name, rate.Rarity, rate.Rate, float64(count)/float64(draws), count)
+	}
+	w.Flush()
+	fmt.Println()
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "rarity\tconfigured\tobserved\tcount\t")
+	for _, rate := range gacha.RarityRates(rates) {
+		count := result.rarityCounts[rate.Rarity]
+		fmt.Fprintf(w, "%d\t%.5f\t%.5f\t%d\t\n", rate.Rarity, rate.Rate, float64(count)/float64(draws), count)
+	}
+	w.Flush()
+	fmt.Println()
+
+	mean, median, p90, max := intervalStats(result.intervals)
+	fmt.Printf("draws to rarity %d or higher: mean %.2f, median %d, p90 %d, max %d (%d hits)\n",
+		top, mean, median, p90, max, len(result.intervals))
+
+	stat, df := chiSquare(observed, expected, draws)
+	fmt.Printf("chi-square vs configured rates: %.3f (df %d, p-value %.4f)\n", stat, df, chiSquarePValue(stat, df))
+	if len(banner.Pity) > 0 || len(banner.Guarantees) > 0 {
+		fmt.Println("note: pity and guarantees shift the observed rates; use -no-pity -no-guarantee to test the raw table")
+	}
+
+	// ピックアップキャラクターがレアリティ内で占めた割合 (すり抜け後の確定があると share より高くなる)
+	for _, rateUp := range banner.RateUps {
+		featured := 0
+		for _, id := range rateUp.CharacterIDs {
+			featured += result.counts[id]
+		}
+		total := result.rarityCounts[rateUp.Rarity]
+		if total == 0 {
+			continue
+		}
+		expectedShare := rateUp.Share
+		if rateUp.Guarantee {
+			expectedShare = 1 / (2 - rateUp.Share)
+		}
+		fmt.Printf("featured share of rarity %d: configured %.3f, expected %.3f, observed %.3f (%d/%d)\n",
+			rateUp.Rarity, rateUp.Share, expectedShare, float64(featured)/float64(total), featured, total)
+	}
+	if len(banner.RateUps) > 0 {
+		fmt.Println("note: the rate-up guarantee shifts the observed rates; use -no-rate-up to test the raw table")
+	}
+}
+
+// compareSamplers はエイリアステーブルによる抽選と累積和の線形走査による抽選の速度を比較します。
+func compareSamplers(banner *gacha.Banner, draws int) {
+	items := banner.Table.Items()
+	state := gacha.PityState{}
+	rnd := gacha.NewSeededRNG(1)
+
+	start := time.Now()
+	for i := 0; i < draws; i++ {
+		banner.Table.Draw(banner.Pity, state, 0, rnd)
+	}
+	alias := time.Since(start)
+
+	start = time.Now()
+	for i := 0; i < draws; i++ {
+		gacha.DrawScan(items, banner.Pity, state, 0, rnd)
+	}
+	scan := time.Since(start)
+
+	results := []struct {
+		name    string
+		elapsed time.Duration
+	}{
+		{"alias table", alias},
+		{"cumulative scan", scan},
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].elapsed < results[j].elapsed })
+
+	fmt.Printf("sampler comparison (%d items, %d draws):\n", len(items), draws)
+	for _, r := range results {
+		fmt.Printf("  %-16s %v (%.1f ns/draw)\n", r.name, r.elapsed, float64(r.elapsed.Nanoseconds())/float64(draws))
+	}
+}
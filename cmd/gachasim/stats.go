@@ -0,0 +1,96 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// chiSquare は観測度数と期待確率からカイ二乗統計量と自由度を返します。
+// 期待確率が 0 の項目は計算から除外します。
+func chiSquare(observed []int, expected []float64, n int) (float64, int) {
+	var stat float64
+	k := 0
+	for i, p := range expected {
+		e := p * float64(n)
+		if e <= 0 {
+			continue
+		}
+		d := float64(observed[i]) - e
+		stat += d * d / e
+		k++
+	}
+	return stat, k - 1
+}
+
+// chiSquarePValue は自由度 df のカイ二乗分布で統計量 stat 以上となる確率(p値)を返します。
+func chiSquarePValue(stat float64, df int) float64 {
+	if df <= 0 {
+		return math.NaN()
+	}
+	return regularizedGammaQ(float64(df)/2, stat/2)
+}
+
+// regularizedGammaQ は正則化された上側不完全ガンマ関数 Q(a, x) を返します。
+func regularizedGammaQ(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	lgamma, _ := math.Lgamma(a)
+	if x < a+1 {
+		// 級数展開で P(a, x) を求める
+		sum, term := 1/a, 1/a
+		for n := 1; n < 1000; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return 1 - sum*math.Exp(-x+a*math.Log(x)-lgamma)
+	}
+
+	// 連分数展開で Q(a, x) を求める (Lentz 法)
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 1000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lgamma) * h
+}
+
+// intervalStats は間隔のリストから平均値・中央値・90パーセンタイル・最大値を返します。
+func intervalStats(intervals []int) (mean float64, median, p90, max int) {
+	if len(intervals) == 0 {
+		return 0, 0, 0, 0
+	}
+	sorted := append([]int(nil), intervals...)
+	sort.Ints(sorted)
+
+	var sum int
+	for _, v := range sorted {
+		sum += v
+	}
+	mean = float64(sum) / float64(len(sorted))
+	median = sorted[len(sorted)/2]
+	p90 = sorted[len(sorted)*9/10]
+	max = sorted[len(sorted)-1]
+	return mean, median, p90, max
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 
+	"my-go-project/internal/gacha"
 	"my-go-project/internal/handler"
 	"my-go-project/internal/repository"
 	"my-go-project/internal/service"
@@ -34,30 +35,108 @@ func main() {
 	// リポジトリの初期化
 	userRepo := repository.NewUserRepository(db)
 	gachaRepo := repository.NewGachaRepository(db)
+	characterRepo := repository.NewCharacterRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	collectionRepo := repository.NewCollectionRepository(db)
+	gameRepo := repository.NewGameRepository(db)
 
 	// サービスの初期化
+	// キャラクターのマスターデータのキャッシュは、参照するサービスで共有します
+	characters := service.NewCharacterCache()
 	userService := service.NewUserService(userRepo)
-	gachaService := service.NewGachaService(gachaRepo)
+	gachaService := service.NewGachaService(gachaRepo, gacha.NewCryptoRNGSource(), characters)
+	characterService := service.NewCharacterService(characterRepo, characters)
+	teamService := service.NewTeamService(teamRepo)
+	collectionService := service.NewCollectionService(collectionRepo, characters)
+	gameService := service.NewGameService(gameRepo)
+
+	// ガチャのマスターデータを検証 (不正なガチャは抽選できない状態で起動する)
+	validations, err := gachaService.ReloadMaster()
+	if err != nil {
+		log.Fatalf("Failed to load gacha master data: %v", err)
+	}
+	for _, v := range validations {
+		if !v.Valid {
+			log.Printf("Gacha %d (%s) is disabled due to invalid master data", v.GachaID, v.Name)
+		}
+	}
+
+	// 図鑑の導入前に獲得していた所持キャラクターを図鑑に登録 (登録済みの場合は何もしない)
+	backfilled, err := collectionService.BackfillCollections()
+	if err != nil {
+		log.Fatalf("Failed to backfill character collections: %v", err)
+	}
+	if backfilled > 0 {
+		log.Printf("Registered %d owned characters to collections", backfilled)
+	}
 
 	// ハンドラーの初期化
 	userHandler := handler.NewUserHandler(userService)
 	gachaHandler := handler.NewGachaHandler(gachaService)
+	characterHandler := handler.NewCharacterHandler(characterService)
+	teamHandler := handler.NewTeamHandler(teamService)
+	collectionHandler := handler.NewCollectionHandler(collectionService)
+	gameHandler := handler.NewGameHandler(gameService)
+	adminHandler := handler.NewAdminHandler(gachaService, characters)
 
 	// ルーターの設定
 	mux := http.NewServeMux()
 
 	// 認証不要なルート
 	mux.HandleFunc("/user/create", userHandler.CreateUser)
+	mux.HandleFunc("/gacha/rates", gachaHandler.GetRates)
+	mux.HandleFunc("/character/master", characterHandler.GetCharacterMaster)
 
 	// 認証が必要なルート
-	authenticatedMux := http.NewServeMux()
-	authenticatedMux.HandleFunc("/user/get", userHandler.GetUser)
-	authenticatedMux.HandleFunc("/user/update", userHandler.UpdateUser)
-	authenticatedMux.HandleFunc("/gacha/draw", gachaHandler.DrawGacha)
-	authenticatedMux.HandleFunc("/character/list", gachaHandler.ListCharacters)
+	authenticatedRoutes := map[string]http.HandlerFunc{
+		"/user/get":                   userHandler.GetUser,
+		"/user/update":                userHandler.UpdateUser,
+		"/gacha/list":                 gachaHandler.ListGachas,
+		"/gacha/draw":                 gachaHandler.DrawGacha,
+		"/gacha/pity":                 gachaHandler.GetPity,
+		"/gacha/history":              gachaHandler.GetHistory,
+		"/gacha/box":                  gachaHandler.GetBox,
+		"/gacha/box/reset":            gachaHandler.ResetBox,
+		"/character/list":             characterHandler.ListCharacters,
+		"/character/feed":             characterHandler.FeedCharacter,
+		"/character/sell":             characterHandler.SellCharacters,
+		"/character/flags":            characterHandler.UpdateCharacterFlags,
+		"/character/collection":       collectionHandler.GetCollection,
+		"/character/collection/claim": collectionHandler.ClaimCollectionRewards,
+		"/item/list":                  characterHandler.ListItems,
+		"/team/list":                  teamHandler.ListTeams,
+		"/team/create":                teamHandler.CreateTeam,
+		"/team/update":                teamHandler.UpdateTeam,
+		"/team/delete":                teamHandler.DeleteTeam,
+		"/game/finish":                gameHandler.FinishGame,
+		"/ranking/list":               gameHandler.ListRanking,
+	}
 
 	// ミドルウェアを適用
-	mux.Handle("/auth/", middleware.AuthMiddleware(authenticatedMux))
+	// トークンは x-token ヘッダー、Bearer トークン、token クッキーの順に探します。
+	// クッキーは CSRF を防ぐため、同じホストのページからのリクエストでのみ受け付けます。
+	authMiddleware := middleware.NewAuthMiddleware(middleware.ChainTokenExtractors(
+		middleware.DefaultTokenExtractor,
+		middleware.CookieTokenExtractor("token"),
+	))
+
+	// API仕様書どおりのパスで提供し、旧来の /auth/ プレフィックス付きのパスも互換のため受け付けます。
+	// 登録していないパスは認証を行わずに 404 を返します。
+	for path, h := range authenticatedRoutes {
+		authenticated := authMiddleware(h)
+		mux.Handle(path, authenticated)
+		mux.Handle("/auth"+path, authenticated)
+	}
+
+	// 管理者用のルート (ADMIN_TOKEN が設定されている場合のみ有効)
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		adminMux := http.NewServeMux()
+		adminMux.HandleFunc("/admin/master/reload", adminHandler.ReloadMaster)
+		adminMux.HandleFunc("/admin/master/validate", adminHandler.ValidateMaster)
+		mux.Handle("/admin/", middleware.AdminMiddleware(adminToken)(adminMux))
+	} else {
+		log.Println("ADMIN_TOKEN is not set; admin endpoints are disabled")
+	}
 
 	// サーバーの起動
 	log.Println("Server is running on port 8080")
@@ -0,0 +1,91 @@
+package gacha
+
+// AliasTable は Walker/Vose のエイリアス法による重み付き抽選テーブルです。
+// 構築に O(n)、1回の抽選に O(1) の計算量がかかります。
+type AliasTable struct {
+	prob  []float64
+	alias []int
+}
+
+// NewAliasTable は weights からエイリアステーブルを構築します。
+// 重みが 0 以下の要素は抽選されません。
+func NewAliasTable(weights []float64) *AliasTable {
+	n := len(weights)
+	t := &AliasTable{
+		prob:  make([]float64, n),
+		alias: make([]int, n),
+	}
+
+	var total float64
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	if n == 0 || total <= 0 {
+		return t
+	}
+
+	// 平均が 1 になるように重みを正規化し、1 未満と 1 以上に振り分ける
+	scaled := make([]float64, n)
+	small := make([]int, 0, n)
+	large := make([]int, 0, n)
+	for i, w := range weights {
+		if w < 0 {
+			w = 0
+		}
+		scaled[i] = w * float64(n) / total
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	for len(small) > 0 && len(large) > 0 {
+		s := small[len(small)-1]
+		small = small[:len(small)-1]
+		l := large[len(large)-1]
+		large = large[:len(large)-1]
+
+		t.prob[s] = scaled[s]
+		t.alias[s] = l
+
+		scaled[l] = scaled[l] + scaled[s] - 1
+		if scaled[l] < 1 {
+			small = append(small, l)
+		} else {
+			large = append(large, l)
+		}
+	}
+
+	// 浮動小数点の誤差で残った要素は確率 1 として扱う
+	for _, i := range large {
+		t.prob[i] = 1
+		t.alias[i] = i
+	}
+	for _, i := range small {
+		t.prob[i] = 1
+		t.alias[i] = i
+	}
+
+	return t
+}
+
+// Len はテーブルの要素数を返します。
+func (t *AliasTable) Len() int {
+	return len(t.prob)
+}
+
+// Sample は重みに比例した確率で要素のインデックスを1つ返します。
+// テーブルが空の場合は false を返します。
+func (t *AliasTable) Sample(rnd RNG) (int, bool) {
+	if len(t.prob) == 0 {
+		return 0, false
+	}
+	i := rnd.Intn(len(t.prob))
+	if rnd.Float64() < t.prob[i] {
+		return i, true
+	}
+	return t.alias[i], true
+}
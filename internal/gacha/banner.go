@@ -0,0 +1,135 @@
+package gacha
+
+import (
+	"my-go-project/internal/model"
+)
+
+// Banner はガチャ1つ分の抽選に必要なマスターデータです。
+// サーバーのガチャ実行とシミュレーター(cmd/gachasim)は、どちらもこの型を通して抽選します。
+// Table とステップごとのテーブルは、ピックアップ設定による重みの調整を適用済みです。
+type Banner struct {
+	Table      *Table
+	Pity       []model.PitySetting
+	Guarantees []model.GachaGuarantee
+	// RateUps はレアリティごとのピックアップ設定で、レアリティの高い順に並びます。
+	RateUps []RateUp
+	// Steps はステップアップガチャのステップ設定で、ステップの昇順に並びます。通常のガチャでは空です。
+	Steps []Step
+	// Box は BOX ガチャの初期の中身です。BOX ガチャは確率テーブルの代わりに Box から非復元抽出で抽選します。
+	Box []model.GachaBoxItem
+}
+
+// NewBanner は確率テーブル、天井設定、確定枠ルール、ピックアップ設定、ステップアップガチャのステップ設定から Banner を生成します。
+func NewBanner(items []model.GachaProbability, pity []model.PitySetting, guarantees []model.GachaGuarantee, rateUps []model.GachaRateUp, steps []model.GachaStep) *Banner {
+	adjusted := RateUpItems(items, rateUps)
+	banner := &Banner{
+		Table:      NewTable(adjusted),
+		Pity:       pity,
+		Guarantees: guarantees,
+	}
+	for _, rateUp := range rateUps {
+		banner.RateUps = append(banner.RateUps, NewRateUp(items, rateUp))
+	}
+	for _, step := range steps {
+		banner.Steps = append(banner.Steps, NewStep(adjusted, step))
+	}
+	return banner
+}
+
+// RateUp は指定されたレアリティのピックアップ設定を返します。設定がない場合は false を返します。
+func (b *Banner) RateUp(rarity int) (RateUp, bool) {
+	for _, rateUp := range b.RateUps {
+		if rateUp.Rarity == rarity {
+			return rateUp, true
+		}
+	}
+	return RateUp{}, false
+}
+
+// IsStepUp はステップアップガチャかどうかを返します。
+func (b *Banner) IsStepUp() bool {
+	return len(b.Steps) > 0
+}
+
+// IsBox は BOX ガチャかどうかを返します。
+func (b *Banner) IsBox() bool {
+	return len(b.Box) > 0
+}
+
+// Step は指定されたステップ(1始まり)の設定を返します。存在しない場合は false を返します。
+func (b *Banner) Step(step int) (Step, bool) {
+	if step < 1 || step > len(b.Steps) {
+		return Step{}, false
+	}
+	return b.Steps[step-1], true
+}
+
+// Pull は連続抽選の1枠分の結果です。
+type Pull struct {
+	Item model.GachaProbability
+	// Guaranteed は確定枠で抽選されたかどうかを表します。
+	Guaranteed bool
+	// Featured はピックアップキャラクター、または BOX ガチャの目玉賞品かどうかを表します。
+	Featured bool
+	// RateUpGuaranteed はすり抜け後の確定によりピックアップキャラクターが選ばれたかどうかを表します。
+	RateUpGuaranteed bool
+}
+
+// DrawBatch は1回のリクエスト分として times 回の連続抽選を行います。
+// 確定枠ルール、天井設定、ピックアップ設定を適用し、pity と rateUp は1回抽選するごとに更新されます。
+// 抽選対象が見つからない枠があった場合は false を返します。
+func (b *Banner) DrawBatch(pity PityState, rateUp RateUpState, times int, rnd RNG) ([]Pull, bool) {
+	return b.drawBatch(b.Table, b.Guarantees, pity, rateUp, times, rnd)
+}
+
+// DrawStep はステップアップガチャの1ステップ分の連続抽選を行います。
+// ステップの排出率補正を適用したテーブルから Times 回抽選し、ガチャの確定枠ルールに加えてステップの確定枠を適用します。
+// 抽選対象が見つからない枠があった場合は false を返します。
+func (b *Banner) DrawStep(pity PityState, rateUp RateUpState, step Step, rnd RNG) ([]Pull, bool) {
+	guarantees := b.Guarantees
+	if guarantee, ok := step.Guarantee(); ok {
+		guarantees = append(append([]model.GachaGuarantee{}, b.Guarantees...), guarantee)
+	}
+	return b.drawBatch(step.Table, guarantees, pity, rateUp, step.Times, rnd)
+}
+
+// drawBatch は table から times 回の連続抽選を行います。
+// ピックアップ設定のあるレアリティを引いたとき、すり抜け後の確定状態であればピックアップキャラクターのみから引き直します。
+func (b *Banner) drawBatch(table *Table, guarantees []model.GachaGuarantee, pity PityState, rateUpState RateUpState, times int, rnd RNG) ([]Pull, bool) {
+	pulls := make([]Pull, 0, times)
+	for i := 0; i < times; i++ {
+		minRarity := GuaranteedMinRarity(guarantees, times, i)
+		item, ok := table.Draw(b.Pity, pity, minRarity, rnd)
+		if !ok {
+			return nil, false
+		}
+		pity.Advance(b.Pity, item.Rarity)
+
+		pull := Pull{Item: item, Guaranteed: minRarity > 0}
+		if rateUp, ok := b.RateUp(item.Rarity); ok {
+			pull.Featured = rateUp.IsFeatured(item.CharacterID)
+			if !pull.Featured && rateUpState[item.Rarity] {
+				if pull.Item, ok = rateUp.Table.Draw(nil, nil, 0, rnd); !ok {
+					return nil, false
+				}
+				pull.Featured = true
+				pull.RateUpGuaranteed = true
+			}
+			rateUpState.Advance(rateUp, pull.Featured)
+		}
+		pulls = append(pulls, pull)
+	}
+	return pulls, true
+}
+
+// Validate は Banner のマスターデータを検証し、見つかった問題点を返します。
+// BOX ガチャの場合は BOX の中身を検証し、確率テーブルなど BOX ガチャで使用しない設定が残っていないかを確認します。
+func (b *Banner) Validate(characters map[int64]model.Character) []Problem {
+	if b.IsBox() {
+		unused := len(b.Table.Items()) > 0 || len(b.Pity) > 0 || len(b.Guarantees) > 0 || len(b.RateUps) > 0 || len(b.Steps) > 0
+		return ValidateBox(b.Box, characters, unused)
+	}
+	problems := Validate(b.Table.Items(), characters, b.Pity, b.Guarantees)
+	problems = append(problems, ValidateRateUps(b.Table.Items(), b.RateUps)...)
+	return append(problems, ValidateSteps(b.Table.Items(), b.Steps)...)
+}
@@ -0,0 +1,94 @@
+package gacha
+
+import (
+	"my-go-project/internal/model"
+)
+
+// Box は BOX ガチャの1ユーザー分の中身です。
+// 抽選は非復元抽出で、各キャラクターが選ばれる確率はその時点の残り数に比例します。
+type Box struct {
+	Items     []model.GachaBoxItem
+	Remaining []int
+}
+
+// NewBox は BOX の初期の中身と、キャラクターごとに引いた数から Box を生成します。
+func NewBox(items []model.GachaBoxItem, drawn map[int64]int) *Box {
+	box := &Box{
+		Items:     items,
+		Remaining: make([]int, len(items)),
+	}
+	for i, item := range items {
+		remaining := item.Quantity - drawn[item.CharacterID]
+		if remaining < 0 {
+			remaining = 0
+		}
+		box.Remaining[i] = remaining
+	}
+	return box
+}
+
+// Total は BOX の初期の中身の合計数を返します。
+func (b *Box) Total() int {
+	total := 0
+	for _, item := range b.Items {
+		total += item.Quantity
+	}
+	return total
+}
+
+// RemainingTotal は BOX の残りの合計数を返します。
+func (b *Box) RemainingTotal() int {
+	total := 0
+	for _, remaining := range b.Remaining {
+		total += remaining
+	}
+	return total
+}
+
+// Draw は BOX から times 個を非復元抽出で引き、Remaining を更新します。
+// 残りが times 個に満たない場合は何も引かずに false を返します。
+func (b *Box) Draw(times int, rnd RNG) ([]Pull, bool) {
+	remaining := b.RemainingTotal()
+	if times > remaining {
+		return nil, false
+	}
+
+	pulls := make([]Pull, 0, times)
+	for n := 0; n < times; n++ {
+		r := rnd.Intn(remaining)
+		for i, count := range b.Remaining {
+			if r >= count {
+				r -= count
+				continue
+			}
+			item := b.Items[i]
+			pulls = append(pulls, Pull{
+				Item: model.GachaProbability{
+					GachaID:     item.GachaID,
+					CharacterID: item.CharacterID,
+					Rarity:      item.Rarity,
+					Probability: float64(count) / float64(remaining),
+				},
+				Featured: item.Featured,
+			})
+			b.Remaining[i]--
+			remaining--
+			break
+		}
+	}
+	return pulls, true
+}
+
+// BoxRates は BOX の初期の中身から、最初の1回を引くときの各キャラクターの排出率を返します。
+func BoxRates(items []model.GachaBoxItem) []Rate {
+	total := NewBox(items, nil).Total()
+	rates := make([]Rate, 0, len(items))
+	for _, item := range items {
+		rate := Rate{CharacterID: item.CharacterID, Rarity: item.Rarity}
+		if total > 0 {
+			rate.Rate = float64(item.Quantity) / float64(total)
+		}
+		rates = append(rates, rate)
+	}
+	return rates
+}
@@ -0,0 +1,20 @@
+package gacha
+
+import (
+	"my-go-project/internal/model"
+)
+
+// GuaranteedMinRarity は times 回の連続抽選のうち index 回目(0始まり)に保証される最低レアリティを返します。
+// 確定枠に該当しない場合は 0 を返します。複数のルールが該当する場合は最も高いレアリティを採用します。
+func GuaranteedMinRarity(guarantees []model.GachaGuarantee, times, index int) int {
+	minRarity := 0
+	for _, guarantee := range guarantees {
+		if guarantee.DrawCount <= 0 || times < guarantee.DrawCount {
+			continue
+		}
+		if (index+1)%guarantee.DrawCount == 0 && guarantee.MinRarity > minRarity {
+			minRarity = guarantee.MinRarity
+		}
+	}
+	return minRarity
+}
@@ -0,0 +1,168 @@
+package gacha
+
+import (
+	"sort"
+
+	"my-go-project/internal/model"
+)
+
+// PityState はユーザーの天井カウンターをレアリティごとに保持します。
+// 値は、そのレアリティ以上のキャラクターが出ていない連続回数です。
+type PityState map[int]int
+
+// NewPityState は保存されている天井カウンターから PityState を生成します。
+func NewPityState(pities []model.UserPity) PityState {
+	state := make(PityState, len(pities))
+	for _, pity := range pities {
+		state[pity.Rarity] = pity.Count
+	}
+	return state
+}
+
+// UserPities は PityState を天井設定ごとの model.UserPity に変換します。
+func (s PityState) UserPities(userID int64, settings []model.PitySetting) []model.UserPity {
+	pities := make([]model.UserPity, 0, len(settings))
+	for _, setting := range settings {
+		pities = append(pities, model.UserPity{
+			UserID:  userID,
+			GachaID: setting.GachaID,
+			Rarity:  setting.Rarity,
+			Count:   s[setting.Rarity],
+		})
+	}
+	return pities
+}
+
+// Advance は抽選結果のレアリティに応じて天井カウンターを更新します。
+func (s PityState) Advance(settings []model.PitySetting, rarity int) {
+	for _, setting := range settings {
+		if rarity >= setting.Rarity {
+			s[setting.Rarity] = 0
+		} else {
+			s[setting.Rarity]++
+		}
+	}
+}
+
+// DrawScan は天井設定を考慮して items から1件を、累積和の線形走査で抽選します。
+// minRarity が 0 より大きい場合は、そのレアリティ以上のキャラクターのみを抽選対象とします(確定枠)。
+// 抽選の分布は Table.Draw と同じで、性能比較や検証に使用します。
+// 抽選対象が見つからなかった場合は false を返します。
+func DrawScan(items []model.GachaProbability, settings []model.PitySetting, state PityState, minRarity int, rnd RNG) (model.GachaProbability, bool) {
+	rarities, totals := rarityTotals(items)
+	scales := rarityScales(rarities, totals, settings, state, minRarity)
+
+	var total float64
+	for _, item := range items {
+		total += item.Probability * scales[item.Rarity]
+	}
+
+	r := rnd.Float64() * total
+	var cumulative float64
+	for _, item := range items {
+		w := item.Probability * scales[item.Rarity]
+		if w == 0 {
+			continue
+		}
+		cumulative += w
+		if r <= cumulative {
+			return item, true
+		}
+	}
+
+	return model.GachaProbability{}, false
+}
+
+// rarityTotals はレアリティの昇順の一覧と、レアリティごとの重みの合計を返します。
+func rarityTotals(items []model.GachaProbability) ([]int, map[int]float64) {
+	totals := make(map[int]float64)
+	var rarities []int
+	for _, item := range items {
+		if _, ok := totals[item.Rarity]; !ok {
+			rarities = append(rarities, item.Rarity)
+		}
+		totals[item.Rarity] += item.Probability
+	}
+	sort.Ints(rarities)
+	return rarities, totals
+}
+
+// rarityScales は天井カウンターと確定枠に応じた、レアリティごとの重みの倍率を返します。
+// 複数の天井設定は組み合わせて適用します。
+//   - 天井に到達している設定と確定枠は下限として扱い、下限未満のレアリティの倍率を 0 にします(最も高い下限が有効です)。
+//   - ソフト天井の範囲内の設定は、下限を適用した後の分布に対して、対象レアリティ以上の排出率を1回ごとに SoftPityStep ずつ引き上げます。
+//     複数の設定がソフト天井の範囲内にある場合はレアリティの低い順に適用するため、最もレアリティの高い設定の排出率が正確に反映されます。
+//
+// 浮動小数点の計算結果を再現可能にするため、rarities の順序で集計します。
+func rarityScales(rarities []int, totals map[int]float64, settings []model.PitySetting, state PityState, minRarity int) map[int]float64 {
+	scales := make(map[int]float64, len(rarities))
+	for _, rarity := range rarities {
+		scales[rarity] = 1
+	}
+
+	// 天井に到達した設定のうち、対象レアリティ以上のキャラクターが存在する最も高いレアリティを下限にする
+	floor := minRarity
+	for _, setting := range settings {
+		next := state[setting.Rarity] + 1
+		if setting.HardPity > 0 && next >= setting.HardPity && setting.Rarity > floor && hasWeightAtLeast(rarities, totals, setting.Rarity) {
+			floor = setting.Rarity
+		}
+	}
+	for _, rarity := range rarities {
+		if rarity < floor {
+			scales[rarity] = 0
+		}
+	}
+
+	// 下限より高いレアリティのソフト天井を、レアリティの低い順に適用する
+	soft := make([]model.PitySetting, 0, len(settings))
+	for _, setting := range settings {
+		next := state[setting.Rarity] + 1
+		if setting.Rarity > floor && setting.SoftPityStart > 0 && next > setting.SoftPityStart {
+			soft = append(soft, setting)
+		}
+	}
+	sort.SliceStable(soft, func(i, j int) bool { return soft[i].Rarity < soft[j].Rarity })
+
+	for _, setting := range soft {
+		var total, upper float64
+		for _, rarity := range rarities {
+			w := totals[rarity] * scales[rarity]
+			total += w
+			if rarity >= setting.Rarity {
+				upper += w
+			}
+		}
+		if upper <= 0 || upper >= total {
+			continue
+		}
+
+		next := state[setting.Rarity] + 1
+		share := upper/total + setting.SoftPityStep*float64(next-setting.SoftPityStart)
+		if share > 1 {
+			share = 1
+		}
+
+		upperScale := share * total / upper
+		lowerScale := (1 - share) * total / (total - upper)
+		for _, rarity := range rarities {
+			if rarity >= setting.Rarity {
+				scales[rarity] *= upperScale
+			} else {
+				scales[rarity] *= lowerScale
+			}
+		}
+	}
+
+	return scales
+}
+
+// hasWeightAtLeast は rarity 以上のレアリティに重みが存在するかどうかを返します。
+func hasWeightAtLeast(rarities []int, totals map[int]float64, rarity int) bool {
+	for _, r := range rarities {
+		if r >= rarity && totals[r] > 0 {
+			return true
+		}
+	}
+	return false
+}
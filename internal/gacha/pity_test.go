@@ -0,0 +1,117 @@
+package gacha
+
+import (
+	"math"
+	"testing"
+
+	"my-go-project/internal/model"
+)
+
+// standardItems と standardPity は schema.sql の Standard ガチャ(ID 1)の初期データです。
+var (
+	standardItems = []model.GachaProbability{
+		{GachaID: 1, CharacterID: 1, Rarity: 1, Probability: 0.4},
+		{GachaID: 1, CharacterID: 2, Rarity: 2, Probability: 0.3},
+		{GachaID: 1, CharacterID: 3, Rarity: 3, Probability: 0.2},
+		{GachaID: 1, CharacterID: 4, Rarity: 4, Probability: 0.08},
+		{GachaID: 1, CharacterID: 5, Rarity: 5, Probability: 0.02},
+	}
+	standardPity = []model.PitySetting{
+		{GachaID: 1, Rarity: 5, HardPity: 80, SoftPityStart: 60, SoftPityStep: 0.05},
+		{GachaID: 1, Rarity: 4, HardPity: 10},
+	}
+)
+
+// rarityShares は補正後のレアリティごとの排出率を返します。
+func rarityShares(items []model.GachaProbability, settings []model.PitySetting, state PityState, minRarity int) map[int]float64 {
+	rarities, totals := rarityTotals(items)
+	scales := rarityScales(rarities, totals, settings, state, minRarity)
+
+	var total float64
+	for _, rarity := range rarities {
+		total += totals[rarity] * scales[rarity]
+	}
+	shares := make(map[int]float64, len(rarities))
+	for _, rarity := range rarities {
+		shares[rarity] = totals[rarity] * scales[rarity] / total
+	}
+	return shares
+}
+
+func TestRarityScalesCombinesPitySettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     PityState
+		minRarity int
+		want      map[int]float64
+	}{
+		{
+			name:  "no pity",
+			state: PityState{5: 0, 4: 0},
+			want:  map[int]float64{1: 0.4, 2: 0.3, 3: 0.2, 4: 0.08, 5: 0.02},
+		},
+		{
+			// レアリティ4の天井が下限になり、その上でレアリティ5のソフト天井を適用する
+			// (下限適用後のレアリティ5の割合 0.2 に 0.05 * 2 を加算)
+			name:  "soft pity on top of a lower hard pity",
+			state: PityState{5: 61, 4: 9},
+			want:  map[int]float64{1: 0, 2: 0, 3: 0, 4: 0.7, 5: 0.3},
+		},
+		{
+			name:  "soft pity only",
+			state: PityState{5: 61, 4: 0},
+			want:  map[int]float64{1: 0.4 * 0.88 / 0.98, 2: 0.3 * 0.88 / 0.98, 3: 0.2 * 0.88 / 0.98, 4: 0.08 * 0.88 / 0.98, 5: 0.12},
+		},
+		{
+			name:  "higher hard pity wins over lower hard pity",
+			state: PityState{5: 79, 4: 9},
+			want:  map[int]float64{1: 0, 2: 0, 3: 0, 4: 0, 5: 1},
+		},
+		{
+			name:      "guarantee acts as a floor",
+			state:     PityState{5: 61, 4: 0},
+			minRarity: 4,
+			want:      map[int]float64{1: 0, 2: 0, 3: 0, 4: 0.7, 5: 0.3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rarityShares(standardItems, standardPity, tt.state, tt.minRarity)
+			for rarity, want := range tt.want {
+				if math.Abs(got[rarity]-want) > 1e-9 {
+					t.Errorf("rarity %d share = %v, want %v", rarity, got[rarity], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDrawRespectsHardPityUnderSoftPity(t *testing.T) {
+	table := NewTable(standardItems)
+	rnd := NewSeededRNG(1)
+
+	const draws = 100000
+	rare := 0
+	for i := 0; i < draws; i++ {
+		item, ok := table.Draw(standardPity, PityState{5: 61, 4: 9}, 0, rnd)
+		if !ok {
+			t.Fatal("draw failed")
+		}
+		if item.Rarity < 4 {
+			t.Fatalf("draw %d returned rarity %d under rarity 4 hard pity", i, item.Rarity)
+		}
+		if item.Rarity == 5 {
+			rare++
+		}
+
+		scan, ok := DrawScan(standardItems, standardPity, PityState{5: 61, 4: 9}, 0, rnd)
+		if !ok || scan.Rarity < 4 {
+			t.Fatalf("DrawScan returned rarity %d under rarity 4 hard pity", scan.Rarity)
+		}
+	}
+
+	if share := float64(rare) / draws; math.Abs(share-0.3) > 0.01 {
+		t.Errorf("rarity 5 share = %v, want about 0.3", share)
+	}
+}
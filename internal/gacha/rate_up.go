@@ -0,0 +1,122 @@
+package gacha
+
+import (
+	"my-go-project/internal/model"
+)
+
+// RateUp はレアリティ1つ分のピックアップ設定です。
+// Table はピックアップキャラクターのみの抽選テーブルで、すり抜け後の確定枠で使用します。
+type RateUp struct {
+	model.GachaRateUp
+	Table    *Table
+	featured map[int64]bool
+}
+
+// NewRateUp は確率テーブルの行とピックアップ設定から RateUp を生成します。
+func NewRateUp(items []model.GachaProbability, rateUp model.GachaRateUp) RateUp {
+	r := RateUp{
+		GachaRateUp: rateUp,
+		featured:    make(map[int64]bool, len(rateUp.CharacterIDs)),
+	}
+	for _, id := range rateUp.CharacterIDs {
+		r.featured[id] = true
+	}
+
+	var featuredItems []model.GachaProbability
+	for _, item := range items {
+		if item.Rarity == rateUp.Rarity && r.featured[item.CharacterID] {
+			featuredItems = append(featuredItems, item)
+		}
+	}
+	r.Table = NewTable(featuredItems)
+	return r
+}
+
+// IsFeatured は指定されたキャラクターがピックアップ対象かどうかを返します。
+func (r RateUp) IsFeatured(characterID int64) bool {
+	return r.featured[characterID]
+}
+
+// RateUpItems はピックアップ設定に従って、各レアリティの合計の重みを変えずにピックアップキャラクターの重みを調整した確率テーブルを返します。
+// ピックアップキャラクターの重みの合計がレアリティの合計の Share になるよう、ピックアップ対象とそれ以外のそれぞれの中で元の重みの比率を保って配分します。
+// レアリティにピックアップ対象のみ、またはピックアップ対象以外のみが含まれる場合、そのレアリティの重みは変更しません。
+func RateUpItems(items []model.GachaProbability, rateUps []model.GachaRateUp) []model.GachaProbability {
+	if len(rateUps) == 0 {
+		return items
+	}
+
+	adjusted := make([]model.GachaProbability, len(items))
+	copy(adjusted, items)
+
+	for _, rateUp := range rateUps {
+		featured := make(map[int64]bool, len(rateUp.CharacterIDs))
+		for _, id := range rateUp.CharacterIDs {
+			featured[id] = true
+		}
+
+		var featuredTotal, otherTotal float64
+		for _, item := range items {
+			if item.Rarity != rateUp.Rarity {
+				continue
+			}
+			if featured[item.CharacterID] {
+				featuredTotal += item.Probability
+			} else {
+				otherTotal += item.Probability
+			}
+		}
+		if featuredTotal <= 0 || otherTotal <= 0 {
+			continue
+		}
+
+		total := featuredTotal + otherTotal
+		for i, item := range items {
+			if item.Rarity != rateUp.Rarity {
+				continue
+			}
+			if featured[item.CharacterID] {
+				adjusted[i].Probability = item.Probability / featuredTotal * rateUp.Share * total
+			} else {
+				adjusted[i].Probability = item.Probability / otherTotal * (1 - rateUp.Share) * total
+			}
+		}
+	}
+
+	return adjusted
+}
+
+// RateUpState はレアリティごとに、次にそのレアリティを引いたときピックアップキャラクターが確定しているかどうかを保持します。
+type RateUpState map[int]bool
+
+// NewRateUpState は保存されているピックアップ確定の状態から RateUpState を生成します。
+func NewRateUpState(rateUps []model.UserGachaRateUp) RateUpState {
+	state := make(RateUpState, len(rateUps))
+	for _, rateUp := range rateUps {
+		state[rateUp.Rarity] = rateUp.Guaranteed
+	}
+	return state
+}
+
+// UserRateUps は RateUpState をピックアップ設定ごとの model.UserGachaRateUp に変換します。
+func (s RateUpState) UserRateUps(userID int64, rateUps []RateUp) []model.UserGachaRateUp {
+	userRateUps := make([]model.UserGachaRateUp, 0, len(rateUps))
+	for _, rateUp := range rateUps {
+		userRateUps = append(userRateUps, model.UserGachaRateUp{
+			UserID:     userID,
+			GachaID:    rateUp.GachaID,
+			Rarity:     rateUp.Rarity,
+			Guaranteed: s[rateUp.Rarity],
+		})
+	}
+	return userRateUps
+}
+
+// Advance はピックアップ設定のあるレアリティを引いた結果に応じて、ピックアップ確定の状態を更新します。
+// ピックアップキャラクターを引いた場合は確定を解除し、すり抜けた場合は確定ありの設定であれば次回を確定にします。
+func (s RateUpState) Advance(rateUp RateUp, featured bool) {
+	if featured {
+		s[rateUp.Rarity] = false
+	} else if rateUp.Guarantee {
+		s[rateUp.Rarity] = true
+	}
+}
@@ -0,0 +1,61 @@
+package gacha
+
+import (
+	"sort"
+
+	"my-go-project/internal/model"
+)
+
+// Rate は1キャラクターの排出率を表します。
+type Rate struct {
+	CharacterID int64
+	Rarity      int
+	Rate        float64
+}
+
+// RarityRate はレアリティごとの排出率の合計を表します。
+type RarityRate struct {
+	Rarity int
+	Rate   float64
+}
+
+// Rates は天井補正が発動していない状態での各キャラクターの排出率を返します。
+// minRarity が 0 より大きい場合は、Draw と同様にそのレアリティ以上のみを対象として正規化します(確定枠)。
+func Rates(items []model.GachaProbability, minRarity int) []Rate {
+	var total float64
+	for _, item := range items {
+		if item.Rarity >= minRarity {
+			total += item.Probability
+		}
+	}
+
+	rates := make([]Rate, 0, len(items))
+	for _, item := range items {
+		if item.Rarity < minRarity {
+			continue
+		}
+		rate := Rate{CharacterID: item.CharacterID, Rarity: item.Rarity}
+		if total > 0 {
+			rate.Rate = item.Probability / total
+		}
+		rates = append(rates, rate)
+	}
+	return rates
+}
+
+// RarityRates はキャラクターごとの排出率をレアリティごとに合計し、レアリティの高い順に返します。
+func RarityRates(rates []Rate) []RarityRate {
+	sums := make(map[int]float64)
+	for _, rate := range rates {
+		sums[rate.Rarity] += rate.Rate
+	}
+
+	rarityRates := make([]RarityRate, 0, len(sums))
+	for rarity, rate := range sums {
+		rarityRates = append(rarityRates, RarityRate{Rarity: rarity, Rate: rate})
+	}
+	sort.Slice(rarityRates, func(i, j int) bool {
+		return rarityRates[i].Rarity > rarityRates[j].Rarity
+	})
+	return rarityRates
+}
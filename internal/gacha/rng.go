@@ -0,0 +1,112 @@
+package gacha
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/bits"
+	mathrand "math/rand"
+)
+
+// RNG はガチャの抽選に使用する乱数生成器です。
+type RNG interface {
+	// Float64 は [0.0, 1.0) の乱数を返します。
+	Float64() float64
+	// Intn は [0, n) の乱数を返します。
+	Intn(n int) int
+}
+
+// RNGSource はガチャ1回分(1リクエスト分)の抽選に使用する RNG を生成します。
+// 返されるシードを NewSeededRNG に渡すと、同じ乱数列を再現できます。
+type RNGSource interface {
+	New() (RNG, int64, error)
+}
+
+// NewSeededRNG は seed から決定的な乱数列を生成する RNG を返します。
+// テストや、監査ログに記録されたシードから過去の抽選を再現する場合に使用します。
+// math/rand の標準のソースはシードを 2^31-1 で割った余りしか使わないため、
+// 64ビットのシードをすべて状態に反映する PCG を使用します。
+func NewSeededRNG(seed int64) RNG {
+	return mathrand.New(newPCGSource(seed))
+}
+
+// PCG (DXSM) の128ビット LCG の乗数と増分です。math/rand/v2 の PCG と同じ値を使用します。
+const (
+	pcgMulHi = 2549297995355413924
+	pcgMulLo = 4865540595714422341
+	pcgIncHi = 6364136223846793005
+	pcgIncLo = 1442695040888963407
+)
+
+// pcgSeedLo は状態の下位64ビットの初期値です。シードは上位64ビットに設定します。
+const pcgSeedLo = 0x9e3779b97f4a7c15
+
+// pcgSource は128ビットの状態を持つ PCG-DXSM による math/rand.Source64 の実装です。
+type pcgSource struct {
+	hi, lo uint64
+}
+
+func newPCGSource(seed int64) *pcgSource {
+	s := &pcgSource{}
+	s.Seed(seed)
+	return s
+}
+
+// Seed は状態をシードで初期化します。シードの64ビットすべてが状態に反映されます。
+func (s *pcgSource) Seed(seed int64) {
+	s.hi = uint64(seed)
+	s.lo = pcgSeedLo
+}
+
+// Uint64 は状態を1つ進めて、64ビットの乱数を返します。
+func (s *pcgSource) Uint64() uint64 {
+	hi, lo := bits.Mul64(s.lo, pcgMulLo)
+	hi += s.hi*pcgMulLo + s.lo*pcgMulHi
+	lo, c := bits.Add64(lo, pcgIncLo, 0)
+	hi, _ = bits.Add64(hi, pcgIncHi, c)
+	s.hi, s.lo = hi, lo
+
+	// DXSM 出力関数
+	const cheapMul = 0xda942042e4dd58b5
+	hi ^= hi >> 32
+	hi *= cheapMul
+	hi ^= hi >> 48
+	hi *= lo | 1
+	return hi
+}
+
+// Int63 は63ビットの非負の乱数を返します。
+func (s *pcgSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// cryptoRNGSource は crypto/rand から取得した予測できないシードで RNG を生成する、本番用の RNGSource です。
+type cryptoRNGSource struct{}
+
+// NewCryptoRNGSource は本番用の RNGSource を生成します。
+func NewCryptoRNGSource() RNGSource {
+	return cryptoRNGSource{}
+}
+
+func (cryptoRNGSource) New() (RNG, int64, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, 0, err
+	}
+	seed := int64(binary.BigEndian.Uint64(b[:]))
+	return NewSeededRNG(seed), seed, nil
+}
+
+// seededRNGSource は固定のシードから RNG を生成する RNGSource です。
+type seededRNGSource struct {
+	seed int64
+}
+
+// NewSeededRNGSource は常に seed から RNG を生成する RNGSource を返します。
+// テストや抽選の再現のために、毎回同じ乱数列を使用したい場合に使用します。
+func NewSeededRNGSource(seed int64) RNGSource {
+	return seededRNGSource{seed}
+}
+
+func (s seededRNGSource) New() (RNG, int64, error) {
+	return NewSeededRNG(s.seed), s.seed, nil
+}
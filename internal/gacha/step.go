@@ -0,0 +1,55 @@
+package gacha
+
+import (
+	"my-go-project/internal/model"
+)
+
+// Step はステップアップガチャの1ステップ分の抽選設定です。
+// Table はステップの排出率補正を適用済みの抽選テーブルです。
+type Step struct {
+	model.GachaStep
+	Table *Table
+}
+
+// NewStep はガチャの確率テーブルにステップの排出率補正を適用して Step を生成します。
+func NewStep(items []model.GachaProbability, step model.GachaStep) Step {
+	return Step{
+		GachaStep: step,
+		Table:     NewTable(BoostItems(items, step.BoostRarity, step.BoostMultiplier)),
+	}
+}
+
+// Guarantee はステップの最後の1枠に適用する確定枠ルールを返します。
+// 確定枠がないステップの場合は false を返します。
+func (s Step) Guarantee() (model.GachaGuarantee, bool) {
+	if s.MinRarity <= 0 {
+		return model.GachaGuarantee{}, false
+	}
+	return model.GachaGuarantee{GachaID: s.GachaID, DrawCount: s.Times, MinRarity: s.MinRarity}, true
+}
+
+// BoostItems は rarity 以上のキャラクターの重みを multiplier 倍にし、合計が 1 になるよう正規化した確率テーブルを返します。
+// multiplier が 1 の場合や補正後の重みの合計が 0 以下になる場合は items をそのまま返します。
+func BoostItems(items []model.GachaProbability, rarity int, multiplier float64) []model.GachaProbability {
+	if multiplier == 1 {
+		return items
+	}
+
+	boosted := make([]model.GachaProbability, len(items))
+	var total float64
+	for i, item := range items {
+		if item.Rarity >= rarity {
+			item.Probability *= multiplier
+		}
+		boosted[i] = item
+		total += item.Probability
+	}
+	if total <= 0 {
+		return items
+	}
+
+	for i := range boosted {
+		boosted[i].Probability /= total
+	}
+	return boosted
+}
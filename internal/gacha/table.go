@@ -0,0 +1,86 @@
+package gacha
+
+import (
+	"my-go-project/internal/model"
+)
+
+// Table はガチャ1つ分の抽選テーブルです。
+// レアリティごとにエイリアステーブルを事前に構築しておき、天井や確定枠による補正はレアリティ単位の倍率として適用するため、
+// 1回の抽選はレアリティの種類数に比例する計算量で済み、キャラクター数には依存しません。
+type Table struct {
+	items    []model.GachaProbability
+	rarities []int
+	totals   map[int]float64
+	members  map[int][]model.GachaProbability
+	aliases  map[int]*AliasTable
+}
+
+// NewTable は確率テーブルの行から抽選テーブルを構築します。
+func NewTable(items []model.GachaProbability) *Table {
+	rarities, totals := rarityTotals(items)
+	t := &Table{
+		items:    items,
+		rarities: rarities,
+		totals:   totals,
+		members:  make(map[int][]model.GachaProbability, len(rarities)),
+		aliases:  make(map[int]*AliasTable, len(rarities)),
+	}
+
+	for _, item := range items {
+		t.members[item.Rarity] = append(t.members[item.Rarity], item)
+	}
+	for rarity, members := range t.members {
+		weights := make([]float64, len(members))
+		for i, item := range members {
+			weights[i] = item.Probability
+		}
+		t.aliases[rarity] = NewAliasTable(weights)
+	}
+
+	return t
+}
+
+// Items は抽選テーブルの元になった確率テーブルの行を返します。
+func (t *Table) Items() []model.GachaProbability {
+	return t.items
+}
+
+// Draw は天井設定を考慮して1件を抽選します。
+// minRarity が 0 より大きい場合は、そのレアリティ以上のキャラクターのみを抽選対象とします(確定枠)。
+// 抽選対象が見つからなかった場合は false を返します。
+func (t *Table) Draw(settings []model.PitySetting, state PityState, minRarity int, rnd RNG) (model.GachaProbability, bool) {
+	scales := rarityScales(t.rarities, t.totals, settings, state, minRarity)
+
+	// 補正後の重みでレアリティを選ぶ
+	var total float64
+	for _, rarity := range t.rarities {
+		total += t.totals[rarity] * scales[rarity]
+	}
+	if total <= 0 {
+		return model.GachaProbability{}, false
+	}
+
+	r := rnd.Float64() * total
+	chosen, found := 0, false
+	for _, rarity := range t.rarities {
+		w := t.totals[rarity] * scales[rarity]
+		if w <= 0 {
+			continue
+		}
+		chosen, found = rarity, true
+		if r < w {
+			break
+		}
+		r -= w
+	}
+	if !found {
+		return model.GachaProbability{}, false
+	}
+
+	// レアリティ内のキャラクターをエイリアステーブルで選ぶ
+	i, ok := t.aliases[chosen].Sample(rnd)
+	if !ok {
+		return model.GachaProbability{}, false
+	}
+	return t.members[chosen][i], true
+}
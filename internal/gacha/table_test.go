@@ -0,0 +1,92 @@
+package gacha
+
+import (
+	"math"
+	"testing"
+
+	"my-go-project/internal/model"
+)
+
+// testItems はレアリティ1〜5に n 件のキャラクターを割り当てた確率テーブルを返します。
+// 同じレアリティ内でも確率が異なるよう、キャラクターごとに重みを変えています。
+func testItems(n int) []model.GachaProbability {
+	rarityWeights := map[int]float64{1: 0.4, 2: 0.3, 3: 0.2, 4: 0.08, 5: 0.02}
+	items := make([]model.GachaProbability, 0, n)
+	for i := 0; i < n; i++ {
+		rarity := i%5 + 1
+		items = append(items, model.GachaProbability{
+			GachaID:     1,
+			CharacterID: int64(i + 1),
+			Rarity:      rarity,
+			Probability: rarityWeights[rarity] * float64(i%7+1),
+		})
+	}
+	return items
+}
+
+func TestTableDrawMatchesDrawScan(t *testing.T) {
+	items := testItems(20)
+	table := NewTable(items)
+	states := []PityState{
+		{5: 0, 4: 0},
+		{5: 70, 4: 3},
+	}
+
+	const draws = 200000
+	for _, state := range states {
+		// 補正後のレアリティの割合と、レアリティ内の重みから期待値を求める
+		shares := rarityShares(items, standardPity, state, 0)
+		_, totals := rarityTotals(items)
+
+		tableCounts := make(map[int64]int, len(items))
+		scanCounts := make(map[int64]int, len(items))
+		tableRnd, scanRnd := NewSeededRNG(1), NewSeededRNG(2)
+		for i := 0; i < draws; i++ {
+			item, ok := table.Draw(standardPity, state, 0, tableRnd)
+			if !ok {
+				t.Fatal("Table.Draw failed")
+			}
+			tableCounts[item.CharacterID]++
+
+			item, ok = DrawScan(items, standardPity, state, 0, scanRnd)
+			if !ok {
+				t.Fatal("DrawScan failed")
+			}
+			scanCounts[item.CharacterID]++
+		}
+
+		for _, item := range items {
+			want := shares[item.Rarity] * item.Probability / totals[item.Rarity]
+			// 標準偏差の5倍を許容する
+			tolerance := 5 * math.Sqrt(want*(1-want)/draws)
+			for name, counts := range map[string]map[int64]int{"Table.Draw": tableCounts, "DrawScan": scanCounts} {
+				got := float64(counts[item.CharacterID]) / draws
+				if math.Abs(got-want) > tolerance {
+					t.Errorf("state %v: %s character %d rate = %v, want %v", state, name, item.CharacterID, got, want)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkTableDraw(b *testing.B) {
+	table := NewTable(testItems(1000))
+	state := PityState{5: 70, 4: 3}
+	rnd := NewSeededRNG(1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		table.Draw(standardPity, state, 0, rnd)
+	}
+}
+
+func BenchmarkDrawScan(b *testing.B) {
+	items := testItems(1000)
+	state := PityState{5: 70, 4: 3}
+	rnd := NewSeededRNG(1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DrawScan(items, standardPity, state, 0, rnd)
+	}
+}
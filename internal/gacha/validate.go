@@ -0,0 +1,231 @@
+package gacha
+
+import (
+	"fmt"
+	"math"
+
+	"my-go-project/internal/model"
+)
+
+// probabilitySumTolerance は確率の合計を 1 とみなす誤差の許容範囲です。
+// gacha_probabilities.probability は FLOAT 型のため、単精度の誤差を許容します。
+const probabilitySumTolerance = 1e-4
+
+// Problem はガチャのマスターデータの問題点を表します。
+type Problem struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	CharacterID int64  `json:"characterID,omitempty"`
+}
+
+// Validate はガチャ1つ分のマスターデータを検証し、見つかった問題点を返します。
+// 問題点がない場合は空のスライスを返します。
+func Validate(items []model.GachaProbability, characters map[int64]model.Character, settings []model.PitySetting, guarantees []model.GachaGuarantee) []Problem {
+	problems := []Problem{}
+
+	if len(items) == 0 {
+		problems = append(problems, Problem{
+			Code:    "empty_table",
+			Message: "probability table has no rows",
+		})
+		return problems
+	}
+
+	var total float64
+	seen := make(map[int64]bool, len(items))
+	rarities := make(map[int]bool)
+	for _, item := range items {
+		if _, ok := characters[item.CharacterID]; !ok {
+			problems = append(problems, Problem{
+				Code:        "unknown_character",
+				Message:     fmt.Sprintf("character %d does not exist", item.CharacterID),
+				CharacterID: item.CharacterID,
+			})
+		}
+		if seen[item.CharacterID] {
+			problems = append(problems, Problem{
+				Code:        "duplicate_character",
+				Message:     fmt.Sprintf("character %d appears more than once", item.CharacterID),
+				CharacterID: item.CharacterID,
+			})
+		}
+		seen[item.CharacterID] = true
+
+		if math.IsNaN(item.Probability) || math.IsInf(item.Probability, 0) || item.Probability <= 0 {
+			problems = append(problems, Problem{
+				Code:        "non_positive_weight",
+				Message:     fmt.Sprintf("character %d has probability %v", item.CharacterID, item.Probability),
+				CharacterID: item.CharacterID,
+			})
+			continue
+		}
+		total += item.Probability
+		rarities[item.Rarity] = true
+	}
+
+	if math.Abs(total-1) > probabilitySumTolerance {
+		problems = append(problems, Problem{
+			Code:    "sum_not_one",
+			Message: fmt.Sprintf("probabilities sum to %v, expected 1", total),
+		})
+	}
+
+	for _, guarantee := range guarantees {
+		if guarantee.DrawCount <= 0 {
+			problems = append(problems, Problem{
+				Code:    "invalid_guarantee",
+				Message: fmt.Sprintf("guarantee has non-positive draw count %d", guarantee.DrawCount),
+			})
+		}
+		if !hasRarityAtLeast(rarities, guarantee.MinRarity) {
+			problems = append(problems, Problem{
+				Code:    "unsatisfiable_guarantee",
+				Message: fmt.Sprintf("no character of rarity %d or higher for guarantee every %d draws", guarantee.MinRarity, guarantee.DrawCount),
+			})
+		}
+	}
+
+	for _, setting := range settings {
+		if setting.HardPity < 0 || setting.SoftPityStart < 0 || setting.SoftPityStep < 0 {
+			problems = append(problems, Problem{
+				Code:    "invalid_pity",
+				Message: fmt.Sprintf("pity for rarity %d has negative values", setting.Rarity),
+			})
+		}
+		if setting.HardPity > 0 && setting.SoftPityStart >= setting.HardPity {
+			problems = append(problems, Problem{
+				Code:    "invalid_pity",
+				Message: fmt.Sprintf("soft pity for rarity %d starts at %d, not before hard pity %d", setting.Rarity, setting.SoftPityStart, setting.HardPity),
+			})
+		}
+		if !hasRarityAtLeast(rarities, setting.Rarity) {
+			problems = append(problems, Problem{
+				Code:    "unsatisfiable_pity",
+				Message: fmt.Sprintf("no character of rarity %d or higher for pity", setting.Rarity),
+			})
+		}
+	}
+
+	return problems
+}
+
+// hasRarityAtLeast は rarities に minRarity 以上のレアリティが含まれるかどうかを返します。
+func hasRarityAtLeast(rarities map[int]bool, minRarity int) bool {
+	for rarity := range rarities {
+		if rarity >= minRarity {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSteps はステップアップガチャのステップ設定を検証し、見つかった問題点を返します。
+// ステップは1から連番で、各ステップは1回以上の抽選回数と0以上のコストを持つ必要があります。
+func ValidateSteps(items []model.GachaProbability, steps []Step) []Problem {
+	var problems []Problem
+
+	rarities := make(map[int]bool)
+	for _, item := range items {
+		if item.Probability > 0 {
+			rarities[item.Rarity] = true
+		}
+	}
+
+	for i, step := range steps {
+		if step.Step != i+1 {
+			problems = append(problems, Problem{
+				Code:    "invalid_step",
+				Message: fmt.Sprintf("step %d found where step %d was expected", step.Step, i+1),
+			})
+		}
+		if step.Times <= 0 || step.Cost < 0 {
+			problems = append(problems, Problem{
+				Code:    "invalid_step",
+				Message: fmt.Sprintf("step %d has times %d and cost %d", step.Step, step.Times, step.Cost),
+			})
+		}
+		if math.IsNaN(step.BoostMultiplier) || math.IsInf(step.BoostMultiplier, 0) || step.BoostMultiplier <= 0 {
+			problems = append(problems, Problem{
+				Code:    "invalid_step",
+				Message: fmt.Sprintf("step %d has boost multiplier %v", step.Step, step.BoostMultiplier),
+			})
+		}
+		if step.MinRarity > 0 && !hasRarityAtLeast(rarities, step.MinRarity) {
+			problems = append(problems, Problem{
+				Code:    "unsatisfiable_guarantee",
+				Message: fmt.Sprintf("no character of rarity %d or higher for step %d", step.MinRarity, step.Step),
+			})
+		}
+	}
+
+	return problems
+}
+
+// ValidateBox は BOX ガチャの中身を検証し、見つかった問題点を返します。
+// unused が true の場合は、BOX ガチャでは使用されない確率テーブル・天井・確定枠・ステップが設定されていることを問題として報告します。
+func ValidateBox(items []model.GachaBoxItem, characters map[int64]model.Character, unused bool) []Problem {
+	problems := []Problem{}
+
+	if unused {
+		problems = append(problems, Problem{
+			Code:    "conflicting_mode",
+			Message: "box gacha must not have probabilities, pity, guarantees, rate-ups or steps",
+		})
+	}
+
+	for _, item := range items {
+		if _, ok := characters[item.CharacterID]; !ok {
+			problems = append(problems, Problem{
+				Code:        "unknown_character",
+				Message:     fmt.Sprintf("character %d does not exist", item.CharacterID),
+				CharacterID: item.CharacterID,
+			})
+		}
+		if item.Quantity <= 0 {
+			problems = append(problems, Problem{
+				Code:        "invalid_box_item",
+				Message:     fmt.Sprintf("character %d has quantity %d", item.CharacterID, item.Quantity),
+				CharacterID: item.CharacterID,
+			})
+		}
+	}
+
+	return problems
+}
+
+// ValidateRateUps はピックアップ設定を検証し、見つかった問題点を返します。
+// Share は 0 より大きく 1 以下で、ピックアップキャラクターは確率テーブルに同じレアリティで含まれている必要があります。
+func ValidateRateUps(items []model.GachaProbability, rateUps []RateUp) []Problem {
+	var problems []Problem
+
+	rarities := make(map[int64]int, len(items))
+	for _, item := range items {
+		rarities[item.CharacterID] = item.Rarity
+	}
+
+	for _, rateUp := range rateUps {
+		if math.IsNaN(rateUp.Share) || rateUp.Share <= 0 || rateUp.Share > 1 {
+			problems = append(problems, Problem{
+				Code:    "invalid_rate_up",
+				Message: fmt.Sprintf("rate-up for rarity %d has share %v", rateUp.Rarity, rateUp.Share),
+			})
+		}
+		if len(rateUp.CharacterIDs) == 0 {
+			problems = append(problems, Problem{
+				Code:    "invalid_rate_up",
+				Message: fmt.Sprintf("rate-up for rarity %d has no featured characters", rateUp.Rarity),
+			})
+		}
+		for _, id := range rateUp.CharacterIDs {
+			if rarity, ok := rarities[id]; !ok || rarity != rateUp.Rarity {
+				problems = append(problems, Problem{
+					Code:        "invalid_rate_up",
+					Message:     fmt.Sprintf("featured character %d is not in the probability table with rarity %d", id, rateUp.Rarity),
+					CharacterID: id,
+				})
+			}
+		}
+	}
+
+	return problems
+}
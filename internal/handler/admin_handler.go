@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"my-go-project/internal/service"
+)
+
+type AdminHandler struct {
+	gachaService service.GachaService
+	characters   *service.CharacterCache
+}
+
+func NewAdminHandler(gachaService service.GachaService, characters *service.CharacterCache) *AdminHandler {
+	return &AdminHandler{gachaService, characters}
+}
+
+// ReloadMaster はキャッシュしているガチャとキャラクターのマスターデータを破棄して再読み込みし、その検証結果を返します。
+// 検証に失敗したガチャは、マスターデータが修正されるまで抽選できなくなります。
+func (h *AdminHandler) ReloadMaster(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.characters.Invalidate()
+	validations, err := h.gachaService.ReloadMaster()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writeValidations(w, validations)
+}
+
+// ValidateMaster はガチャのマスターデータをデータベースから読み込んで検証し、見つかった問題点を返します。
+// キャッシュしているマスターデータは置き換えないため、抽選には影響しません。
+func (h *AdminHandler) ValidateMaster(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	validations, err := h.gachaService.ValidateMaster()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writeValidations(w, validations)
+}
+
+// writeValidations はマスターデータの検証結果をレスポンスとして書き込みます。
+func writeValidations(w http.ResponseWriter, validations []service.MasterValidation) {
+	valid := true
+	for _, v := range validations {
+		valid = valid && v.Valid
+	}
+
+	res := struct {
+		Valid  bool                       `json:"valid"`
+		Gachas []service.MasterValidation `json:"gachas"`
+	}{
+		Valid:  valid,
+		Gachas: validations,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
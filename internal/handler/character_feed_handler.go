@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"my-go-project/internal/service"
+	"my-go-project/pkg/middleware"
+)
+
+// FeedCharacter は所持キャラクターにアイテムや他の所持キャラクターを消費して経験値を与えます。
+func (h *CharacterHandler) FeedCharacter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req service.FeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserCharacterID <= 0 {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.characterService.FeedCharacter(userID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
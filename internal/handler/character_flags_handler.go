@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"my-go-project/internal/service"
+	"my-go-project/pkg/middleware"
+)
+
+// UpdateCharacterFlags は所持キャラクターのロックとお気に入りをまとめて更新します。
+func (h *CharacterHandler) UpdateCharacterFlags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req service.CharacterFlagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.UserCharacterIDs) == 0 {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	characters, err := h.characterService.UpdateCharacterFlags(userID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	res := struct {
+		Characters []service.UserCharacterResponse `json:"characters"`
+	}{
+		Characters: characters,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
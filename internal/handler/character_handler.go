@@ -8,8 +8,17 @@ import (
 	"my-go-project/pkg/middleware"
 )
 
+type CharacterHandler struct {
+	characterService service.CharacterService
+}
+
+func NewCharacterHandler(characterService service.CharacterService) *CharacterHandler {
+	return &CharacterHandler{characterService}
+}
+
 // ListCharacters はユーザーが所持するキャラクター一覧を取得します。
-func (h *GachaHandler) ListCharacters(w http.ResponseWriter, r *http.Request) {
+// クエリパラメータで並び替え(sort, order)、絞り込み(rarity, characterID, locked, favorite)、ページング(cursor, limit)を指定できます。
+func (h *CharacterHandler) ListCharacters(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 		return
@@ -22,16 +31,78 @@ func (h *GachaHandler) ListCharacters(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	characters, err := h.gachaService.ListCharacters(userID)
+	limit, ok := queryInt(r, "limit", 0)
+	if !ok || limit < 0 {
+		http.Error(w, "Bad Request: invalid limit", http.StatusBadRequest)
+		return
+	}
+	rarity, ok := queryInt(r, "rarity", 0)
+	if !ok || rarity < 0 {
+		http.Error(w, "Bad Request: invalid rarity", http.StatusBadRequest)
+		return
+	}
+	characterID, ok := queryInt64(r, "characterID", 0)
+	if !ok || characterID < 0 {
+		http.Error(w, "Bad Request: invalid characterID", http.StatusBadRequest)
+		return
+	}
+	locked, ok := queryBool(r, "locked")
+	if !ok {
+		http.Error(w, "Bad Request: invalid locked", http.StatusBadRequest)
+		return
+	}
+	favorite, ok := queryBool(r, "favorite")
+	if !ok {
+		http.Error(w, "Bad Request: invalid favorite", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	list, err := h.characterService.ListCharacters(userID, service.CharacterListQuery{
+		Cursor:      query.Get("cursor"),
+		Limit:       limit,
+		Sort:        query.Get("sort"),
+		Order:       query.Get("order"),
+		Rarity:      rarity,
+		CharacterID: characterID,
+		Locked:      locked,
+		Favorite:    favorite,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GetCharacterMaster はすべてのキャラクターのマスターデータを取得します。認証は不要です。
+// レスポンスには ETag ヘッダーを付与し、If-None-Match が一致する場合は本文なしで 304 を返します。
+func (h *CharacterHandler) GetCharacterMaster(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	master, err := h.characterService.GetCharacterMaster()
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeServiceError(w, err)
+		return
+	}
+
+	// キャッシュを使う場合も毎回 ETag で検証させる
+	w.Header().Set("ETag", master.ETag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if matchETag(r.Header.Get("If-None-Match"), master.ETag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
 	res := struct {
-		Characters []service.UserCharacterResponse `json:"characters"`
+		Characters []service.CharacterMaster `json:"characters"`
 	}{
-		Characters: characters,
+		Characters: master.Characters,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
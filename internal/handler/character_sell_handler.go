@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"my-go-project/pkg/middleware"
+)
+
+// SellCharacters は所持キャラクターをまとめて売却し、レアリティごとの報酬を付与します。
+func (h *CharacterHandler) SellCharacters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		UserCharacterIDs []int64 `json:"userCharacterIDs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.UserCharacterIDs) == 0 {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.characterService.SellCharacters(userID, req.UserCharacterIDs)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
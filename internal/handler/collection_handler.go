@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"my-go-project/internal/service"
+	"my-go-project/pkg/middleware"
+)
+
+type CollectionHandler struct {
+	collectionService service.CollectionService
+}
+
+func NewCollectionHandler(collectionService service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{collectionService}
+}
+
+// GetCollection はユーザーの図鑑(一度でも獲得したことがあるキャラクターと達成率)を取得します。
+func (h *CollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	collection, err := h.collectionService.GetCollection(userID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collection)
+}
+
+// ClaimCollectionRewards は図鑑の達成率が条件を満たしている未受け取りの達成報酬をすべて受け取ります。
+func (h *CollectionHandler) ClaimCollectionRewards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	claim, err := h.collectionService.ClaimCollectionRewards(userID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(claim)
+}
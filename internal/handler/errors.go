@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"my-go-project/internal/service"
+)
+
+// writeServiceError はサービス層のエラーを対応するHTTPステータスコードのレスポンスに変換します。
+func writeServiceError(w http.ResponseWriter, err error) {
+	var coinsErr *service.InsufficientCoinsError
+	switch {
+	case errors.As(err, &coinsErr):
+		// 残高不足はクライアントが残高を表示できるよう JSON で返す
+		res := struct {
+			Error    string `json:"error"`
+			Required int64  `json:"required"`
+			Coin     int64  `json:"coin"`
+		}{
+			Error:    service.ErrInsufficientCoins.Error(),
+			Required: coinsErr.Required,
+			Coin:     coinsErr.Balance,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(res)
+	case errors.Is(err, service.ErrGachaNotFound),
+		errors.Is(err, service.ErrCharacterNotOwned),
+		errors.Is(err, service.ErrTeamNotFound):
+		http.Error(w, "Not Found: "+err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		http.Error(w, "Unprocessable Entity: "+err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, service.ErrGachaUnavailable):
+		http.Error(w, "Service Unavailable: "+err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, service.ErrGachaNotOpen),
+		errors.Is(err, service.ErrStepUpCompleted),
+		errors.Is(err, service.ErrInvalidTimes),
+		errors.Is(err, service.ErrInvalidStepTimes),
+		errors.Is(err, service.ErrNotBoxGacha),
+		errors.Is(err, service.ErrBoxInsufficient),
+		errors.Is(err, service.ErrBoxNotResettable),
+		errors.Is(err, service.ErrInvalidListQuery),
+		errors.Is(err, service.ErrInvalidCursor),
+		errors.Is(err, service.ErrInsufficientItems),
+		errors.Is(err, service.ErrInvalidFeed),
+		errors.Is(err, service.ErrMaxLevel),
+		errors.Is(err, service.ErrInvalidSell),
+		errors.Is(err, service.ErrCharacterLocked),
+		errors.Is(err, service.ErrInvalidCharacterFlags),
+		errors.Is(err, service.ErrCharacterInTeam),
+		errors.Is(err, service.ErrInvalidTeam),
+		errors.Is(err, service.ErrTeamLimit),
+		errors.Is(err, service.ErrInvalidScore):
+		http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
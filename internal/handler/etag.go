@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"strings"
+)
+
+// matchETag は If-None-Match ヘッダーの値 header が etag に一致するかどうかを返します。
+// カンマ区切りの複数の値、"*"、弱い比較のための W/ 接頭辞に対応します。
+func matchETag(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || strings.TrimPrefix(value, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
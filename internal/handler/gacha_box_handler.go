@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"my-go-project/pkg/middleware"
+)
+
+// GetBox はユーザーの BOX ガチャの現在の BOX の中身を取得します。
+func (h *GachaHandler) GetBox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// 常設ガチャは BOX ガチャではないため、gachaID は必須
+	gachaID, ok := queryInt64(r, "gachaID", 0)
+	if !ok || gachaID <= 0 {
+		http.Error(w, "Bad Request: invalid gachaID", http.StatusBadRequest)
+		return
+	}
+
+	box, err := h.gachaService.GetBox(userID, gachaID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(box)
+}
+
+// ResetBox はユーザーの BOX ガチャの BOX を初期状態に戻します。
+// BOX が空になったか、目玉賞品を引いた後のみリセットできます。
+func (h *GachaHandler) ResetBox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		GachaID int64 `json:"gachaID"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.GachaID <= 0 {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	box, err := h.gachaService.ResetBox(userID, req.GachaID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(box)
+}
@@ -3,11 +3,15 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"my-go-project/internal/service"
 	"my-go-project/pkg/middleware"
 )
 
+// maxIdempotencyKeyLength は Idempotency-Key ヘッダーの最大長です。
+const maxIdempotencyKeyLength = 255
+
 type GachaHandler struct {
 	gachaService service.GachaService
 }
@@ -31,26 +35,104 @@ func (h *GachaHandler) DrawGacha(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Times int `json:"times"`
+		GachaID int64 `json:"gachaID"`
+		Times   int   `json:"times"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Times <= 0 {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
+	if req.Times <= 0 || req.Times > service.MaxDrawTimes {
+		http.Error(w, "Bad Request: times must be between 1 and "+strconv.Itoa(service.MaxDrawTimes), http.StatusBadRequest)
+		return
+	}
+	if req.GachaID == 0 {
+		req.GachaID = service.DefaultGachaID
+	}
+
+	// 再送時に同じ結果を返すための冪等キー (任意)
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		http.Error(w, "Bad Request: Idempotency-Key is too long", http.StatusBadRequest)
+		return
+	}
 
-	results, err := h.gachaService.DrawGacha(userID, req.Times)
+	res, err := h.gachaService.DrawGacha(userID, req.GachaID, req.Times, idempotencyKey)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeServiceError(w, err)
+		return
+	}
+	if res.Replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// GetPity はユーザーの天井カウンターを取得します。
+func (h *GachaHandler) GetPity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gachaID, ok := queryInt64(r, "gachaID", service.DefaultGachaID)
+	if !ok {
+		http.Error(w, "Bad Request: invalid gachaID", http.StatusBadRequest)
+		return
+	}
+
+	pity, err := h.gachaService.GetPity(userID, gachaID)
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
 	res := struct {
-		Results []service.GachaResult `json:"results"`
+		Pity []service.PityStatus `json:"pity"`
 	}{
-		Results: results,
+		Pity: pity,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
 
+// ListGachas は開催中のガチャ(バナー)一覧を取得します。
+// ステップアップガチャにはリクエストしたユーザーの現在のステップが含まれます。
+func (h *GachaHandler) ListGachas(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gachas, err := h.gachaService.ListGachas(userID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	res := struct {
+		Gachas []service.GachaBanner `json:"gachas"`
+	}{
+		Gachas: gachas,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"my-go-project/pkg/middleware"
+)
+
+// GetHistory はユーザーのガチャ実行履歴を取得します。
+func (h *GachaHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	cursor, ok := queryInt64(r, "cursor", 0)
+	if !ok || cursor < 0 {
+		http.Error(w, "Bad Request: invalid cursor", http.StatusBadRequest)
+		return
+	}
+	limit, ok := queryInt(r, "limit", 0)
+	if !ok || limit < 0 {
+		http.Error(w, "Bad Request: invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.gachaService.GetHistory(userID, cursor, limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"my-go-project/internal/service"
+)
+
+// GetRates はガチャの提供割合を取得します。認証は不要です。
+// gachaID が指定されない場合は開催中のすべてのガチャの提供割合を返します。
+func (h *GachaHandler) GetRates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gachaID, ok := queryInt64(r, "gachaID", 0)
+	if !ok || gachaID < 0 {
+		http.Error(w, "Bad Request: invalid gachaID", http.StatusBadRequest)
+		return
+	}
+
+	rates, err := h.gachaService.GetRates(gachaID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	res := struct {
+		Gachas []service.GachaRates `json:"gachas"`
+	}{
+		Gachas: rates,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
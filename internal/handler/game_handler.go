@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"my-go-project/internal/service"
+	"my-go-project/pkg/middleware"
+)
+
+type GameHandler struct {
+	gameService service.GameService
+}
+
+func NewGameHandler(gameService service.GameService) *GameHandler {
+	return &GameHandler{gameService}
+}
+
+// FinishGame はゲームのスコアを記録し、スコアに応じたコインを付与します。
+func (h *GameHandler) FinishGame(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// コンテキストからユーザーIDを取得
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Score *int64 `json:"score"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Score == nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.gameService.FinishGame(userID, *req.Score)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ListRanking は最高スコアのランキングを取得します。
+// クエリパラメータで取得を開始する位置(start)と取得件数(limit)、前のページの続きから取得するためのカーソル(cursor)を指定できます。
+// start と cursor は同時に指定できません。
+func (h *GameHandler) ListRanking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	start, ok := queryInt(r, "start", 1)
+	if !ok || start <= 0 {
+		http.Error(w, "Bad Request: invalid start", http.StatusBadRequest)
+		return
+	}
+	limit, ok := queryInt(r, "limit", 0)
+	if !ok || limit < 0 {
+		http.Error(w, "Bad Request: invalid limit", http.StatusBadRequest)
+		return
+	}
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" && r.URL.Query().Has("start") {
+		http.Error(w, "Bad Request: start and cursor cannot be combined", http.StatusBadRequest)
+		return
+	}
+
+	ranking, err := h.gameService.GetRanking(start, cursor, limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ranking)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// queryInt64 はクエリパラメータ name を int64 として取得します。
+// 指定されていない場合は def を返し、数値として解釈できない場合は false を返します。
+func queryInt64(r *http.Request, name string, def int64) (int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, true
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// queryBool はクエリパラメータ name を bool として取得します。
+// 指定されていない場合は nil を返し、true / false として解釈できない場合は false を返します。
+func queryBool(r *http.Request, name string) (*bool, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, false
+	}
+	return &b, true
+}
+
+// queryInt はクエリパラメータ name を int として取得します。
+// 指定されていない場合は def を返し、数値として解釈できない場合は false を返します。
+func queryInt(r *http.Request, name string, def int) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
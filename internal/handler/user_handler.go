@@ -82,9 +82,11 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	res := struct {
 		ID   int64  `json:"id"`
 		Name string `json:"name"`
+		Coin int64  `json:"coin"`
 	}{
 		ID:   user.ID,
 		Name: user.Name,
+		Coin: user.Coin,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
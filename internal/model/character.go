@@ -2,11 +2,27 @@ package model
 
 import "time"
 
+// Duplicate modes decide what happens when a user obtains a character they already own.
+const (
+    // DuplicateModeKeep adds another user_characters row for the duplicate.
+    DuplicateModeKeep = "keep"
+    // DuplicateModeItem converts the duplicate into DuplicateItemQuantity of the item DuplicateItemID.
+    DuplicateModeItem = "item"
+    // DuplicateModeLimitBreak increments the limit-break level of the owned row up to MaxLimitBreak,
+    // and converts the duplicate into the item once the level is maxed out.
+    DuplicateModeLimitBreak = "limit_break"
+)
+
 // Character represents a character that can be obtained via gacha.
+// DuplicateMode is one of the DuplicateMode constants. DuplicateItemID is 0 when duplicates are not converted into an item.
 type Character struct {
-    ID        int64     `json:"id"`
-    Name      string    `json:"name"`
-    Rarity    int       `json:"rarity"`
-    CreatedAt time.Time `json:"created_at"`
-    UpdatedAt time.Time `json:"updated_at"`
+    ID                    int64     `json:"id"`
+    Name                  string    `json:"name"`
+    Rarity                int       `json:"rarity"`
+    DuplicateMode         string    `json:"duplicate_mode"`
+    DuplicateItemID       int64     `json:"duplicate_item_id"`
+    DuplicateItemQuantity int       `json:"duplicate_item_quantity"`
+    MaxLimitBreak         int       `json:"max_limit_break"`
+    CreatedAt             time.Time `json:"created_at"`
+    UpdatedAt             time.Time `json:"updated_at"`
 }
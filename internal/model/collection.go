@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// UserCollection represents a character that a user has obtained at least once.
+// The row is kept after the owned character is sold or consumed.
+type UserCollection struct {
+    UserID          int64     `json:"user_id"`
+    CharacterID     int64     `json:"character_id"`
+    FirstAcquiredAt time.Time `json:"first_acquired_at"`
+}
+
+// CollectionReward represents a reward granted once when a user's collection reaches Percent completion.
+// Rarity 0 means the completion across all characters; otherwise only characters of the rarity are counted.
+// ItemID is 0 when the reward has no item.
+type CollectionReward struct {
+    ID           int64 `json:"id"`
+    Rarity       int   `json:"rarity"`
+    Percent      int   `json:"percent"`
+    Coin         int64 `json:"coin"`
+    ItemID       int64 `json:"item_id"`
+    ItemQuantity int64 `json:"item_quantity"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Gacha represents a gacha banner with its own probability table.
+// Cost is the number of coins consumed per draw.
+// A nil StartAt or EndAt means the banner has no bound on that side.
+// StepLoop is only meaningful for step-up banners and tells whether the steps start over after the final one.
+type Gacha struct {
+    ID        int64      `json:"id"`
+    Name      string     `json:"name"`
+    Cost      int64      `json:"cost"`
+    StartAt   *time.Time `json:"start_at,omitempty"`
+    EndAt     *time.Time `json:"end_at,omitempty"`
+    StepLoop  bool       `json:"step_loop"`
+    CreatedAt time.Time  `json:"created_at"`
+    UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsOpen reports whether the banner can be drawn at the given time.
+func (g *Gacha) IsOpen(now time.Time) bool {
+    if g.StartAt != nil && now.Before(*g.StartAt) {
+        return false
+    }
+    if g.EndAt != nil && !now.Before(*g.EndAt) {
+        return false
+    }
+    return true
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// GachaBoxItem represents the initial quantity of a character in a box gacha.
+// Every user's box starts with Quantity copies of the character, which are drawn without replacement.
+// Drawing a Featured character allows the user to reset the box before it is empty.
+type GachaBoxItem struct {
+    GachaID     int64 `json:"gacha_id"`
+    CharacterID int64 `json:"character_id"`
+    Rarity      int   `json:"rarity"`
+    Quantity    int   `json:"quantity"`
+    Featured    bool  `json:"featured"`
+}
+
+// UserGachaBox represents a user's current box on a box gacha.
+// BoxNumber starts at 1 and increases every time the box is reset.
+type UserGachaBox struct {
+    UserID        int64     `json:"user_id"`
+    GachaID       int64     `json:"gacha_id"`
+    BoxNumber     int       `json:"box_number"`
+    FeaturedDrawn bool      `json:"featured_drawn"`
+    UpdatedAt     time.Time `json:"updated_at"`
+}
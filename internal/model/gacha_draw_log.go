@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// GachaDrawLog represents an audit record of a single /gacha/draw batch.
+// Seed is the RNG seed used for the batch and Results holds the JSON encoded results,
+// including the user_characters rows that were created.
+type GachaDrawLog struct {
+    ID        int64     `json:"id"`
+    BatchID   string    `json:"batch_id"`
+    UserID    int64     `json:"user_id"`
+    GachaID   int64     `json:"gacha_id"`
+    Times     int       `json:"times"`
+    Seed      int64     `json:"seed"`
+    Results   []byte    `json:"results"`
+    CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// GachaDrawRequest represents a /gacha/draw request identified by a client-supplied idempotency key.
+// Response holds the JSON encoded result of the first draw and is nil while the draw is in progress.
+type GachaDrawRequest struct {
+    UserID         int64     `json:"user_id"`
+    IdempotencyKey string    `json:"idempotency_key"`
+    GachaID        int64     `json:"gacha_id"`
+    Times          int       `json:"times"`
+    Response       []byte    `json:"response"`
+    CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// GachaGuarantee represents a guaranteed slot rule on a banner.
+// When a single request draws at least DrawCount times, the last slot of every
+// DrawCount draws is drawn only from characters whose rarity is MinRarity or higher.
+type GachaGuarantee struct {
+    GachaID   int64 `json:"gacha_id"`
+    DrawCount int   `json:"draw_count"`
+    MinRarity int   `json:"min_rarity"`
+}
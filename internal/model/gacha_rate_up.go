@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// GachaRateUp represents a rate-up (featured characters) on a rarity of a banner.
+// Share is the fraction of the rarity's pulls that go to the featured characters, split by their weights.
+// When Guarantee is set, losing the rate-up (pulling a non-featured character of the rarity)
+// guarantees a featured character the next time the rarity is pulled.
+type GachaRateUp struct {
+    GachaID      int64   `json:"gacha_id"`
+    Rarity       int     `json:"rarity"`
+    Share        float64 `json:"share"`
+    Guarantee    bool    `json:"guarantee"`
+    CharacterIDs []int64 `json:"character_ids"`
+}
+
+// UserGachaRateUp represents whether a user's next pull of the rarity on a banner is guaranteed to be featured.
+type UserGachaRateUp struct {
+    UserID     int64     `json:"user_id"`
+    GachaID    int64     `json:"gacha_id"`
+    Rarity     int       `json:"rarity"`
+    Guaranteed bool      `json:"guaranteed"`
+    UpdatedAt  time.Time `json:"updated_at"`
+}
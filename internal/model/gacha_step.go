@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// GachaStep represents one step of a step-up banner.
+// Each step is drawn as a single request of Times draws for Cost coins in total.
+// MinRarity guarantees the last draw of the step (0 disables it).
+// The weights of rarity BoostRarity or higher are multiplied by BoostMultiplier during the step (1 leaves the rates unchanged).
+type GachaStep struct {
+    GachaID         int64   `json:"gacha_id"`
+    Step            int     `json:"step"`
+    Times           int     `json:"times"`
+    Cost            int64   `json:"cost"`
+    MinRarity       int     `json:"min_rarity"`
+    BoostRarity     int     `json:"boost_rarity"`
+    BoostMultiplier float64 `json:"boost_multiplier"`
+}
+
+// UserGachaStep represents a user's progress on a step-up banner.
+// CurrentStep is the step drawn next. Completed is set once the final step of a non-looping banner has been drawn.
+type UserGachaStep struct {
+    UserID      int64     `json:"user_id"`
+    GachaID     int64     `json:"gacha_id"`
+    CurrentStep int       `json:"current_step"`
+    Completed   bool      `json:"completed"`
+    UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// GameScore represents the result of one game played by a user and the coins awarded for it.
+type GameScore struct {
+    ID        int64     `json:"id"`
+    UserID    int64     `json:"user_id"`
+    Score     int64     `json:"score"`
+    Coin      int64     `json:"coin"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// UserHighScore represents the best score of a user, which is used for the ranking.
+// AchievedAt is when the score was first reached and breaks ties. Name is joined from the users table.
+type UserHighScore struct {
+    UserID     int64     `json:"user_id"`
+    Name       string    `json:"name"`
+    Score      int64     `json:"score"`
+    AchievedAt time.Time `json:"achieved_at"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Item represents an item such as shards or materials that users can hold in quantity.
+// Exp is the experience a character gains when one of the item is fed as a material; 0 means it is not a material.
+type Item struct {
+    ID        int64     `json:"id"`
+    Name      string    `json:"name"`
+    Exp       int64     `json:"exp"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserItem represents the quantity of an item that a user holds.
+// Name and Exp are joined from the items table.
+type UserItem struct {
+    UserID    int64     `json:"user_id"`
+    ItemID    int64     `json:"item_id"`
+    Quantity  int64     `json:"quantity"`
+    Name      string    `json:"name"`
+    Exp       int64     `json:"exp"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package model
+
+// LevelCurve represents the total experience required to reach a level for characters of a rarity.
+// The highest level defined for a rarity is its max level.
+type LevelCurve struct {
+    Rarity      int   `json:"rarity"`
+    Level       int   `json:"level"`
+    RequiredExp int64 `json:"required_exp"`
+}
+
+// FeedExp represents the experience gained by feeding an owned character of a rarity to another.
+type FeedExp struct {
+    Rarity int   `json:"rarity"`
+    Exp    int64 `json:"exp"`
+}
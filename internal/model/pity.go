@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// PitySetting represents the pity (ceiling) configuration for a rarity on a banner.
+// HardPity is the draw count at which the rarity is guaranteed (0 disables it).
+// After SoftPityStart draws the rate of the rarity climbs by SoftPityStep per draw (0 disables it).
+type PitySetting struct {
+    GachaID       int64   `json:"gacha_id"`
+    Rarity        int     `json:"rarity"`
+    HardPity      int     `json:"hard_pity"`
+    SoftPityStart int     `json:"soft_pity_start"`
+    SoftPityStep  float64 `json:"soft_pity_step"`
+}
+
+// UserPity represents how many draws a user has made on a banner since last obtaining the rarity or higher.
+type UserPity struct {
+    UserID    int64     `json:"user_id"`
+    GachaID   int64     `json:"gacha_id"`
+    Rarity    int       `json:"rarity"`
+    Count     int       `json:"count"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
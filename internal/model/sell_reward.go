@@ -0,0 +1,10 @@
+package model
+
+// SellReward represents the payout for selling an owned character of a rarity.
+// ItemID is 0 when the payout has no item.
+type SellReward struct {
+    Rarity       int   `json:"rarity"`
+    Coin         int64 `json:"coin"`
+    ItemID       int64 `json:"item_id"`
+    ItemQuantity int64 `json:"item_quantity"`
+}
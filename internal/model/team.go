@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Team represents a named preset of a user's owned characters.
+// Members are ordered by Slot, which starts at 1.
+type Team struct {
+    ID        int64        `json:"id"`
+    UserID    int64        `json:"user_id"`
+    Name      string       `json:"name"`
+    Members   []TeamMember `json:"members"`
+    CreatedAt time.Time    `json:"created_at"`
+    UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// TeamMember represents an owned character assigned to a slot of a team.
+type TeamMember struct {
+    TeamID          int64 `json:"team_id"`
+    Slot            int   `json:"slot"`
+    UserCharacterID int64 `json:"user_character_id"`
+}
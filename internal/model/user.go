@@ -6,6 +6,7 @@ import "time"
 type User struct {
     ID        int64     `json:"id"`
     Name      string    `json:"name"`
+    Coin      int64     `json:"coin"`
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
 }
@@ -3,9 +3,42 @@ package model
 import "time"
 
 // UserCharacter represents a character that a user has obtained.
+// LimitBreak is the number of duplicates merged into the row. Exp is the total experience gained so far
+// and Level is derived from it by the level curve of the character's rarity.
+// Locked characters cannot be consumed by feeding or selling. Favorite is a marker for the player only.
+// Name and Rarity are joined from the characters table.
 type UserCharacter struct {
     ID          int64     `json:"user_character_id"`
     UserID      int64     `json:"user_id"`
     CharacterID int64     `json:"character_id"`
+    LimitBreak  int       `json:"limit_break"`
+    Level       int       `json:"level"`
+    Exp         int64     `json:"exp"`
+    Locked      bool      `json:"locked"`
+    Favorite    bool      `json:"favorite"`
     AcquiredAt  time.Time `json:"acquired_at"`
+    Name        string    `json:"name"`
+    Rarity      int       `json:"rarity"`
+}
+
+// Sort keys for listing user characters.
+const (
+    UserCharacterSortAcquiredAt = "acquired_at"
+    UserCharacterSortRarity     = "rarity"
+    UserCharacterSortName       = "name"
+)
+
+// UserCharacterQuery describes a page of a user's characters.
+// Rarity and CharacterID filter the rows when they are non-zero, and Locked and Favorite when they are non-nil.
+// Rows are ordered by Sort and then by ID in the same direction, so After (the last row of the previous page) identifies the next page uniquely.
+type UserCharacterQuery struct {
+    UserID      int64
+    Rarity      int
+    CharacterID int64
+    Locked      *bool
+    Favorite    *bool
+    Sort        string
+    Desc        bool
+    After       *UserCharacter
+    Limit       int
 }
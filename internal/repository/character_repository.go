@@ -2,22 +2,145 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 
 	"my-go-project/internal/model"
 )
 
-// NewGachaRepository は新しい GachaRepository を生成します。
-func NewGachaRepository(db *sql.DB) GachaRepository {
-	return &gachaRepository{db}
+// CharacterRepository は所持キャラクター(一覧、育成、売却、ロック・お気に入り)と所持アイテム関連のデータベース操作を定義するインターフェースです。
+type CharacterRepository interface {
+	GetCharacters() ([]model.Character, error)
+	GetUserCharacters(query model.UserCharacterQuery) ([]model.UserCharacter, error)
+	GetUserCharactersByIDs(userID int64, userCharacterIDs []int64) (map[int64]model.UserCharacter, error)
+	DeleteUserCharacters(userID int64, userCharacterIDs []int64) error
+	UpdateUserCharacterLevel(userCharacterID int64, level int, exp int64) error
+	UpdateUserCharacterFlags(userID int64, userCharacterIDs []int64, locked, favorite *bool) error
+	GetTeamAssignedCharacters(userCharacterIDs []int64) (map[int64]bool, error)
+	GetUserItems(userID int64) ([]model.UserItem, error)
+	GetUserItemsByIDs(userID int64, itemIDs []int64) (map[int64]model.UserItem, error)
+	AddUserItems(userID int64, quantities map[int64]int64) error
+	GetLevelCurve(rarity int) ([]model.LevelCurve, error)
+	GetFeedExps() ([]model.FeedExp, error)
+	GetSellRewards() ([]model.SellReward, error)
+	GetUserCoinForUpdate(userID int64) (int64, error)
+	AddUserCoin(userID, delta int64) error
+	Transaction(fn func(repo CharacterRepository) error) error
 }
 
-// GetUserCharacters は指定されたユーザーが所持するキャラクターを取得します。
-func (r *gachaRepository) GetUserCharacters(userID int64) ([]model.UserCharacter, error) {
+// characterRepository は CharacterRepository インターフェースを実装する構造体です。
+type characterRepository struct {
+	db dbtx
+}
+
+// NewCharacterRepository は新しい CharacterRepository を生成します。
+func NewCharacterRepository(db *sql.DB) CharacterRepository {
+	return &characterRepository{db}
+}
+
+// Transaction は fn を1つのトランザクション内で実行します。
+// fn に渡される CharacterRepository の操作はすべて同じトランザクションで行われ、fn がエラーを返した場合はロールバックされます。
+func (r *characterRepository) Transaction(fn func(repo CharacterRepository) error) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		return fn(&characterRepository{tx})
+	})
+}
+
+// userCharacterSortColumns は所持キャラクター一覧の並び替えキーと、並び替えに使用する列の対応です。
+var userCharacterSortColumns = map[string]string{
+	model.UserCharacterSortAcquiredAt: "uc.acquired_at",
+	model.UserCharacterSortRarity:     "c.rarity",
+	model.UserCharacterSortName:       "c.name",
+}
+
+// GetCharacters はキャラクターのマスターデータの一覧で返すすべてのキャラクターを取得します。
+func (r *characterRepository) GetCharacters() ([]model.Character, error) {
+	return getCharacters(r.db)
+}
+
+// getCharacters はすべてのキャラクターのマスターデータを取得します。
+// キャラクターのマスターデータを参照する各リポジトリの GetCharacters から呼び出します。
+func getCharacters(db dbtx) ([]model.Character, error) {
+	rows, err := db.Query(`
+		SELECT id, name, rarity, duplicate_mode, COALESCE(duplicate_item_id, 0), duplicate_item_quantity, max_limit_break, created_at, updated_at
+		FROM characters
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var characters []model.Character
+	for rows.Next() {
+		var c model.Character
+		if err := rows.Scan(&c.ID, &c.Name, &c.Rarity, &c.DuplicateMode, &c.DuplicateItemID, &c.DuplicateItemQuantity, &c.MaxLimitBreak, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		characters = append(characters, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return characters, nil
+}
+
+// GetUserCharacters は指定された条件に一致する所持キャラクターを、キャラクター名とレアリティを含めて取得します。
+// キャラクター情報は characters テーブルとの結合により1回のクエリで取得します。
+// 並び順は query.Sort の列、同じ値の場合は所持キャラクターIDで決まり、query.After が指定された場合はその行より後ろの行のみを取得します。
+func (r *characterRepository) GetUserCharacters(query model.UserCharacterQuery) ([]model.UserCharacter, error) {
+	column, ok := userCharacterSortColumns[query.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort key: %q", query.Sort)
+	}
+	order, cmp := "ASC", ">"
+	if query.Desc {
+		order, cmp = "DESC", "<"
+	}
+
+	where := []string{"uc.user_id = ?"}
+	args := []interface{}{query.UserID}
+	if query.Rarity > 0 {
+		where = append(where, "c.rarity = ?")
+		args = append(args, query.Rarity)
+	}
+	if query.CharacterID > 0 {
+		where = append(where, "uc.character_id = ?")
+		args = append(args, query.CharacterID)
+	}
+	if query.Locked != nil {
+		where = append(where, "uc.locked = ?")
+		args = append(args, *query.Locked)
+	}
+	if query.Favorite != nil {
+		where = append(where, "uc.favorite = ?")
+		args = append(args, *query.Favorite)
+	}
+	if after := query.After; after != nil {
+		var value interface{}
+		switch query.Sort {
+		case model.UserCharacterSortAcquiredAt:
+			value = after.AcquiredAt
+		case model.UserCharacterSortRarity:
+			value = after.Rarity
+		case model.UserCharacterSortName:
+			value = after.Name
+		}
+		where = append(where, "("+column+" "+cmp+" ? OR ("+column+" = ? AND uc.id "+cmp+" ?))")
+		args = append(args, value, value, after.ID)
+	}
+	args = append(args, query.Limit)
+
 	rows, err := r.db.Query(`
-		SELECT id, user_id, character_id, acquired_at
-		FROM user_characters
-		WHERE user_id = ?
-	`, userID)
+		SELECT uc.id, uc.user_id, uc.character_id, uc.limit_break, uc.level, uc.exp, uc.locked, uc.favorite, uc.acquired_at, c.name, c.rarity
+		FROM user_characters uc
+		JOIN characters c ON c.id = uc.character_id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+column+` `+order+`, uc.id `+order+`
+		LIMIT ?
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +149,7 @@ func (r *gachaRepository) GetUserCharacters(userID int64) ([]model.UserCharacter
 	var userCharacters []model.UserCharacter
 	for rows.Next() {
 		var uc model.UserCharacter
-		if err := rows.Scan(&uc.ID, &uc.UserID, &uc.CharacterID, &uc.AcquiredAt); err != nil {
+		if err := rows.Scan(&uc.ID, &uc.UserID, &uc.CharacterID, &uc.LimitBreak, &uc.Level, &uc.Exp, &uc.Locked, &uc.Favorite, &uc.AcquiredAt, &uc.Name, &uc.Rarity); err != nil {
 			return nil, err
 		}
 		userCharacters = append(userCharacters, uc)
@@ -37,4 +160,329 @@ func (r *gachaRepository) GetUserCharacters(userID int64) ([]model.UserCharacter
 	}
 
 	return userCharacters, nil
-}
\ No newline at end of file
+}
+
+// GetUserCharactersByIDs は所持キャラクターIDを指定して、指定されたユーザーが所持するキャラクターを
+// キャラクター名とレアリティを含めて、所持キャラクターIDをキーとしたマップで取得します。
+// 他のユーザーの所持キャラクターや存在しないIDはマップに含まれません。
+// トランザクション内で呼び出された場合は、育成や消費の同時実行を防ぐため行ロックを取得します。
+func (r *characterRepository) GetUserCharactersByIDs(userID int64, userCharacterIDs []int64) (map[int64]model.UserCharacter, error) {
+	return getUserCharactersByIDs(r.db, userID, userCharacterIDs)
+}
+
+// getUserCharactersByIDs は CharacterRepository と TeamRepository の GetUserCharactersByIDs の実装です。
+func getUserCharactersByIDs(db dbtx, userID int64, userCharacterIDs []int64) (map[int64]model.UserCharacter, error) {
+	userCharacters := make(map[int64]model.UserCharacter)
+	if len(userCharacterIDs) == 0 {
+		return userCharacters, nil
+	}
+
+	args := []interface{}{userID}
+	for _, id := range userCharacterIDs {
+		args = append(args, id)
+	}
+	rows, err := db.Query(`
+		SELECT uc.id, uc.user_id, uc.character_id, uc.limit_break, uc.level, uc.exp, uc.locked, uc.favorite, uc.acquired_at, c.name, c.rarity
+		FROM user_characters uc
+		JOIN characters c ON c.id = uc.character_id
+		WHERE uc.user_id = ? AND uc.id IN (`+placeholders(len(userCharacterIDs))+`)
+		ORDER BY uc.id
+		FOR UPDATE
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var uc model.UserCharacter
+		if err := rows.Scan(&uc.ID, &uc.UserID, &uc.CharacterID, &uc.LimitBreak, &uc.Level, &uc.Exp, &uc.Locked, &uc.Favorite, &uc.AcquiredAt, &uc.Name, &uc.Rarity); err != nil {
+			return nil, err
+		}
+		userCharacters[uc.ID] = uc
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userCharacters, nil
+}
+
+// DeleteUserCharacters は指定されたユーザーの所持キャラクターを削除します。
+func (r *characterRepository) DeleteUserCharacters(userID int64, userCharacterIDs []int64) error {
+	if len(userCharacterIDs) == 0 {
+		return nil
+	}
+
+	args := []interface{}{userID}
+	for _, id := range userCharacterIDs {
+		args = append(args, id)
+	}
+	_, err := r.db.Exec(`
+		DELETE FROM user_characters
+		WHERE user_id = ? AND id IN (`+placeholders(len(userCharacterIDs))+`)
+	`, args...)
+	return err
+}
+
+// UpdateUserCharacterLevel は所持キャラクターのレベルと累計経験値を更新します。
+func (r *characterRepository) UpdateUserCharacterLevel(userCharacterID int64, level int, exp int64) error {
+	_, err := r.db.Exec(`
+		UPDATE user_characters
+		SET level = ?, exp = ?
+		WHERE id = ?
+	`, level, exp, userCharacterID)
+	return err
+}
+
+// UpdateUserCharacterFlags は指定されたユーザーの所持キャラクターのロックとお気に入りを更新します。
+// locked と favorite は nil の場合は変更しません。
+func (r *characterRepository) UpdateUserCharacterFlags(userID int64, userCharacterIDs []int64, locked, favorite *bool) error {
+	if len(userCharacterIDs) == 0 || (locked == nil && favorite == nil) {
+		return nil
+	}
+
+	args := []interface{}{locked == nil, locked, favorite == nil, favorite, userID}
+	for _, id := range userCharacterIDs {
+		args = append(args, id)
+	}
+	_, err := r.db.Exec(`
+		UPDATE user_characters
+		SET locked = IF(?, locked, ?), favorite = IF(?, favorite, ?)
+		WHERE user_id = ? AND id IN (`+placeholders(len(userCharacterIDs))+`)
+	`, args...)
+	return err
+}
+
+// GetTeamAssignedCharacters は userCharacterIDs のうち、いずれかのチームに編成されている所持キャラクターIDを返します。
+func (r *characterRepository) GetTeamAssignedCharacters(userCharacterIDs []int64) (map[int64]bool, error) {
+	assigned := make(map[int64]bool)
+	if len(userCharacterIDs) == 0 {
+		return assigned, nil
+	}
+
+	args := make([]interface{}, 0, len(userCharacterIDs))
+	for _, id := range userCharacterIDs {
+		args = append(args, id)
+	}
+	rows, err := r.db.Query(`
+		SELECT DISTINCT user_character_id
+		FROM team_members
+		WHERE user_character_id IN (`+placeholders(len(userCharacterIDs))+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		assigned[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return assigned, nil
+}
+
+// GetUserItems は指定されたユーザーが所持するアイテムを、アイテム名を含めてアイテムIDの昇順に取得します。
+// 所持数が 0 のアイテムは含みません。
+func (r *characterRepository) GetUserItems(userID int64) ([]model.UserItem, error) {
+	rows, err := r.db.Query(`
+		SELECT ui.user_id, ui.item_id, ui.quantity, i.name, i.exp, ui.updated_at
+		FROM user_items ui
+		JOIN items i ON i.id = ui.item_id
+		WHERE ui.user_id = ? AND ui.quantity > 0
+		ORDER BY ui.item_id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.UserItem
+	for rows.Next() {
+		var item model.UserItem
+		if err := rows.Scan(&item.UserID, &item.ItemID, &item.Quantity, &item.Name, &item.Exp, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// GetUserItemsByIDs は指定されたユーザーが所持するアイテムのうち、itemIDs に含まれるものを
+// アイテム名と経験値を含めて、アイテムIDをキーとしたマップで取得します。所持していないアイテムはマップに含まれません。
+// トランザクション内で呼び出された場合は、消費の同時実行を防ぐため行ロックを取得します。
+func (r *characterRepository) GetUserItemsByIDs(userID int64, itemIDs []int64) (map[int64]model.UserItem, error) {
+	items := make(map[int64]model.UserItem)
+	if len(itemIDs) == 0 {
+		return items, nil
+	}
+
+	args := []interface{}{userID}
+	for _, id := range itemIDs {
+		args = append(args, id)
+	}
+	rows, err := r.db.Query(`
+		SELECT ui.user_id, ui.item_id, ui.quantity, i.name, i.exp, ui.updated_at
+		FROM user_items ui
+		JOIN items i ON i.id = ui.item_id
+		WHERE ui.user_id = ? AND ui.item_id IN (`+placeholders(len(itemIDs))+`)
+		FOR UPDATE
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.UserItem
+		if err := rows.Scan(&item.UserID, &item.ItemID, &item.Quantity, &item.Name, &item.Exp, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items[item.ItemID] = item
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// AddUserItems は育成で消費したアイテムの減算と、売却で獲得したアイテムの加算を行います。
+// 所持数の確認は呼び出し側で行います。
+func (r *characterRepository) AddUserItems(userID int64, quantities map[int64]int64) error {
+	return addUserItems(r.db, userID, quantities)
+}
+
+// addUserItems はユーザーの所持アイテムを、アイテムIDごとに quantities の数だけ加算します。負の数を指定した場合は減算します。
+// アイテムを付与または消費する各リポジトリの AddUserItems から呼び出します。
+func addUserItems(db dbtx, userID int64, quantities map[int64]int64) error {
+	return runInTx(db, func(tx dbtx) error {
+		stmt, err := tx.Prepare(`
+			INSERT INTO user_items (user_id, item_id, quantity, updated_at)
+			VALUES (?, ?, ?, NOW())
+			ON DUPLICATE KEY UPDATE quantity = quantity + VALUES(quantity), updated_at = VALUES(updated_at)
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for itemID, quantity := range quantities {
+			if _, err := stmt.Exec(userID, itemID, quantity); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetLevelCurve は指定されたレアリティのレベルごとの必要累計経験値を、レベルの昇順に取得します。
+func (r *characterRepository) GetLevelCurve(rarity int) ([]model.LevelCurve, error) {
+	rows, err := r.db.Query(`
+		SELECT rarity, level, required_exp
+		FROM level_curves
+		WHERE rarity = ?
+		ORDER BY level
+	`, rarity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var curve []model.LevelCurve
+	for rows.Next() {
+		var level model.LevelCurve
+		if err := rows.Scan(&level.Rarity, &level.Level, &level.RequiredExp); err != nil {
+			return nil, err
+		}
+		curve = append(curve, level)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return curve, nil
+}
+
+// GetFeedExps は所持キャラクターを育成素材として消費したときに得られる経験値を、レアリティごとに取得します。
+func (r *characterRepository) GetFeedExps() ([]model.FeedExp, error) {
+	rows, err := r.db.Query(`
+		SELECT rarity, exp
+		FROM feed_exps
+		ORDER BY rarity
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exps []model.FeedExp
+	for rows.Next() {
+		var exp model.FeedExp
+		if err := rows.Scan(&exp.Rarity, &exp.Exp); err != nil {
+			return nil, err
+		}
+		exps = append(exps, exp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return exps, nil
+}
+
+// GetSellRewards は所持キャラクターを売却したときの報酬を、レアリティごとに取得します。
+func (r *characterRepository) GetSellRewards() ([]model.SellReward, error) {
+	rows, err := r.db.Query(`
+		SELECT rarity, coin, COALESCE(item_id, 0), item_quantity
+		FROM sell_rewards
+		ORDER BY rarity
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rewards []model.SellReward
+	for rows.Next() {
+		var reward model.SellReward
+		if err := rows.Scan(&reward.Rarity, &reward.Coin, &reward.ItemID, &reward.ItemQuantity); err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, reward)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rewards, nil
+}
+
+// GetUserCoinForUpdate は売却で獲得したコインを加算する前に、ユーザーのコイン残高をロックして取得します。
+func (r *characterRepository) GetUserCoinForUpdate(userID int64) (int64, error) {
+	return getUserCoinForUpdate(r.db, userID)
+}
+
+// AddUserCoin は売却で獲得したコインをユーザーのコイン残高に加算します。
+func (r *characterRepository) AddUserCoin(userID, delta int64) error {
+	return addUserCoin(r.db, userID, delta)
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"database/sql"
+
+	"my-go-project/internal/model"
+)
+
+// CollectionRepository はキャラクター図鑑と達成報酬関連のデータベース操作を定義するインターフェースです。
+// 図鑑への登録はガチャの実行時に GachaRepository.AddUserCollections で行います。
+type CollectionRepository interface {
+	GetCharacters() ([]model.Character, error)
+	GetUserCollections(userID int64) ([]model.UserCollection, error)
+	GetCollectionRewards() ([]model.CollectionReward, error)
+	GetGrantedCollectionRewards(userID int64) (map[int64]bool, error)
+	AddGrantedCollectionRewards(userID int64, rewardIDs []int64) error
+	GetUserCoinForUpdate(userID int64) (int64, error)
+	AddUserCoin(userID, delta int64) error
+	AddUserItems(userID int64, quantities map[int64]int64) error
+	BackfillUserCollections() (int64, error)
+	Transaction(fn func(repo CollectionRepository) error) error
+}
+
+// collectionRepository は CollectionRepository インターフェースを実装する構造体です。
+type collectionRepository struct {
+	db dbtx
+}
+
+// NewCollectionRepository は新しい CollectionRepository を生成します。
+func NewCollectionRepository(db *sql.DB) CollectionRepository {
+	return &collectionRepository{db}
+}
+
+// Transaction は fn を1つのトランザクション内で実行します。
+// fn に渡される CollectionRepository の操作はすべて同じトランザクションで行われ、fn がエラーを返した場合はロールバックされます。
+func (r *collectionRepository) Transaction(fn func(repo CollectionRepository) error) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		return fn(&collectionRepository{tx})
+	})
+}
+
+// GetCharacters は図鑑に並べるすべてのキャラクターを取得します。
+func (r *collectionRepository) GetCharacters() ([]model.Character, error) {
+	return getCharacters(r.db)
+}
+
+// GetUserCollections は指定されたユーザーの図鑑に登録されたキャラクターを、キャラクターIDの昇順に取得します。
+func (r *collectionRepository) GetUserCollections(userID int64) ([]model.UserCollection, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id, character_id, first_acquired_at
+		FROM user_collections
+		WHERE user_id = ?
+		ORDER BY character_id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []model.UserCollection
+	for rows.Next() {
+		var collection model.UserCollection
+		if err := rows.Scan(&collection.UserID, &collection.CharacterID, &collection.FirstAcquiredAt); err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+// GetCollectionRewards は図鑑の達成報酬を、レアリティと達成率の昇順に取得します。
+func (r *collectionRepository) GetCollectionRewards() ([]model.CollectionReward, error) {
+	rows, err := r.db.Query(`
+		SELECT id, rarity, percent, coin, COALESCE(item_id, 0), item_quantity
+		FROM collection_rewards
+		ORDER BY rarity, percent, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rewards []model.CollectionReward
+	for rows.Next() {
+		var reward model.CollectionReward
+		if err := rows.Scan(&reward.ID, &reward.Rarity, &reward.Percent, &reward.Coin, &reward.ItemID, &reward.ItemQuantity); err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, reward)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rewards, nil
+}
+
+// GetGrantedCollectionRewards は指定されたユーザーに付与済みの図鑑の達成報酬のIDを取得します。
+// 行ロックは取得しません。報酬の付与は GetUserCoinForUpdate でユーザーの行をロックしてから呼び出します。
+func (r *collectionRepository) GetGrantedCollectionRewards(userID int64) (map[int64]bool, error) {
+	rows, err := r.db.Query(`
+		SELECT reward_id
+		FROM user_collection_rewards
+		WHERE user_id = ?
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	granted := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		granted[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return granted, nil
+}
+
+// AddGrantedCollectionRewards は図鑑の達成報酬をユーザーに付与済みとして記録します。
+func (r *collectionRepository) AddGrantedCollectionRewards(userID int64, rewardIDs []int64) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		stmt, err := tx.Prepare(`
+			INSERT INTO user_collection_rewards (user_id, reward_id, granted_at)
+			VALUES (?, ?, NOW())
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, rewardID := range rewardIDs {
+			if _, err := stmt.Exec(userID, rewardID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetUserCoinForUpdate はユーザーの行をロックしてコイン残高を取得します。
+// 同じユーザーの達成報酬の受け取りを直列化するため、付与済みの報酬を確認する前に呼び出します。
+func (r *collectionRepository) GetUserCoinForUpdate(userID int64) (int64, error) {
+	return getUserCoinForUpdate(r.db, userID)
+}
+
+// AddUserCoin は達成報酬のコインをユーザーのコイン残高に加算します。
+func (r *collectionRepository) AddUserCoin(userID, delta int64) error {
+	return addUserCoin(r.db, userID, delta)
+}
+
+// AddUserItems は達成報酬のアイテムをユーザーの所持アイテムに加算します。
+func (r *collectionRepository) AddUserItems(userID int64, quantities map[int64]int64) error {
+	return addUserItems(r.db, userID, quantities)
+}
+
+// BackfillUserCollections は図鑑の導入前に獲得していた所持キャラクターを図鑑に登録し、登録した件数を返します。
+// 登録済みのキャラクターは変更しないため、何度実行しても結果は変わりません。
+// 売却や育成で失ったキャラクターは所持キャラクターに残っていないため登録されません。
+func (r *collectionRepository) BackfillUserCollections() (int64, error) {
+	result, err := r.db.Exec(`
+		INSERT IGNORE INTO user_collections (user_id, character_id, first_acquired_at)
+		SELECT user_id, character_id, MIN(acquired_at)
+		FROM user_characters
+		GROUP BY user_id, character_id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
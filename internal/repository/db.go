@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// dbtx は *sql.DB と *sql.Tx に共通するメソッドを定義するインターフェースです。
+// リポジトリはこのインターフェースを通してクエリを実行するため、トランザクションの内外で同じ実装を利用できます。
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// runInTx は fn をトランザクション内で実行します。
+// db がすでにトランザクションの場合は新たに開始せず、そのまま fn に渡します。
+func runInTx(db dbtx, fn func(tx dbtx) error) error {
+	if tx, ok := db.(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return errors.New("repository: unsupported database handle")
+	}
+
+	tx, err := sqlDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// placeholders は IN 句で使用する n 個のプレースホルダー ("?, ?, ?") を返します。
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?, ", n-1) + "?"
+}
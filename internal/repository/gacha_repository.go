@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
 	"my-go-project/internal/model"
@@ -9,24 +10,153 @@ import (
 
 // GachaRepository はガチャ関連のデータベース操作を定義するインターフェースです。
 type GachaRepository interface {
-	GetGachaItems() ([]model.GachaProbability, float64, error)
-	AddUserCharacters(userID int64, characterIDs []int64) error
-	GetUserCharacters(userID int64) ([]model.UserCharacter, error)
-	GetCharacterName(characterID int64) (string, error)
+	GetGacha(gachaID int64) (*model.Gacha, error)
+	GetGachas() ([]model.Gacha, error)
+	GetOpenGachas(now time.Time) ([]model.Gacha, error)
+	GetGachaItems(gachaID int64) ([]model.GachaProbability, float64, error)
+	GetGachaGuarantees(gachaID int64) ([]model.GachaGuarantee, error)
+	GetUserCoinForUpdate(userID int64) (int64, error)
+	AddUserCoin(userID, delta int64) error
+	ReserveDrawRequest(userID int64, key string, gachaID int64, times int) (*model.GachaDrawRequest, error)
+	SaveDrawResponse(userID int64, key string, gachaID int64, times int, response []byte) error
+	CreateDrawLog(log *model.GachaDrawLog) (int64, error)
+	GetDrawLogs(userID, beforeID int64, limit int) ([]model.GachaDrawLog, error)
+	AddUserCharacters(userID int64, characterIDs []int64) ([]int64, error)
+	GetOwnedCharacters(userID int64, characterIDs []int64) (map[int64]model.UserCharacter, error)
+	AddLimitBreaks(increments map[int64]int) error
+	AddUserItems(userID int64, quantities map[int64]int64) error
+	GetCharacters() ([]model.Character, error)
+	AddUserCollections(userID int64, characterIDs []int64) ([]int64, error)
+	GetPitySettings(gachaID int64) ([]model.PitySetting, error)
+	GetUserPities(userID, gachaID int64) ([]model.UserPity, error)
+	SaveUserPities(userID, gachaID int64, pities []model.UserPity) error
+	GetGachaSteps(gachaID int64) ([]model.GachaStep, error)
+	GetUserGachaStep(userID, gachaID int64) (*model.UserGachaStep, error)
+	SaveUserGachaStep(progress *model.UserGachaStep) error
+	GetGachaBoxItems(gachaID int64) ([]model.GachaBoxItem, error)
+	GetUserGachaBox(userID, gachaID int64) (*model.UserGachaBox, error)
+	SaveUserGachaBox(box *model.UserGachaBox) error
+	GetUserGachaBoxDraws(userID, gachaID int64) (map[int64]int, error)
+	AddUserGachaBoxDraws(userID, gachaID int64, drawn map[int64]int) error
+	ResetUserGachaBox(box *model.UserGachaBox) error
+	GetGachaRateUps(gachaID int64) ([]model.GachaRateUp, error)
+	GetUserRateUps(userID, gachaID int64) ([]model.UserGachaRateUp, error)
+	SaveUserRateUps(userID, gachaID int64, rateUps []model.UserGachaRateUp) error
+	Transaction(fn func(repo GachaRepository) error) error
 }
 
 // gachaRepository は GachaRepository インターフェースを実装する構造体です。
 type gachaRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
-// GetGachaItems はガチャに使用されるキャラクターとその確率を取得します。
-// 戻り値にはキャラクターのリストと全確率の合計が含まれます。
-func (r *gachaRepository) GetGachaItems() ([]model.GachaProbability, float64, error) {
+// NewGachaRepository は新しい GachaRepository を生成します。
+func NewGachaRepository(db *sql.DB) GachaRepository {
+	return &gachaRepository{db}
+}
+
+// Transaction は fn を1つのトランザクション内で実行します。
+// fn に渡される GachaRepository の操作はすべて同じトランザクションで行われ、fn がエラーを返した場合はロールバックされます。
+func (r *gachaRepository) Transaction(fn func(repo GachaRepository) error) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		return fn(&gachaRepository{tx})
+	})
+}
+
+// GetGacha は指定されたIDのガチャ(バナー)を取得します。
+// 存在しない場合は sql.ErrNoRows を返します。
+func (r *gachaRepository) GetGacha(gachaID int64) (*model.Gacha, error) {
+	row := r.db.QueryRow(`
+		SELECT id, name, cost, start_at, end_at, step_loop, created_at, updated_at
+		FROM gachas
+		WHERE id = ?
+	`, gachaID)
+
+	return scanGacha(row)
+}
+
+// GetGachas は開催期間にかかわらず、すべてのガチャ(バナー)を取得します。
+func (r *gachaRepository) GetGachas() ([]model.Gacha, error) {
 	rows, err := r.db.Query(`
-		SELECT character_id, probability
-		FROM gacha_probabilities
+		SELECT id, name, cost, start_at, end_at, step_loop, created_at, updated_at
+		FROM gachas
+		ORDER BY id
 	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanGachas(rows)
+}
+
+// GetOpenGachas は指定された時刻に開催中のガチャ(バナー)を取得します。
+func (r *gachaRepository) GetOpenGachas(now time.Time) ([]model.Gacha, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, cost, start_at, end_at, step_loop, created_at, updated_at
+		FROM gachas
+		WHERE (start_at IS NULL OR start_at <= ?)
+		  AND (end_at IS NULL OR end_at > ?)
+		ORDER BY id
+	`, now, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanGachas(rows)
+}
+
+// scanGachas は gachas テーブルの複数行を model.Gacha のスライスに読み込みます。
+func scanGachas(rows *sql.Rows) ([]model.Gacha, error) {
+	var gachas []model.Gacha
+	for rows.Next() {
+		gacha, err := scanGacha(rows)
+		if err != nil {
+			return nil, err
+		}
+		gachas = append(gachas, *gacha)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return gachas, nil
+}
+
+// scanner は *sql.Row と *sql.Rows に共通する Scan メソッドを定義するインターフェースです。
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanGacha は gachas テーブルの1行を model.Gacha に読み込みます。
+func scanGacha(s scanner) (*model.Gacha, error) {
+	var gacha model.Gacha
+	var startAt, endAt sql.NullTime
+	if err := s.Scan(&gacha.ID, &gacha.Name, &gacha.Cost, &startAt, &endAt, &gacha.StepLoop, &gacha.CreatedAt, &gacha.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if startAt.Valid {
+		gacha.StartAt = &startAt.Time
+	}
+	if endAt.Valid {
+		gacha.EndAt = &endAt.Time
+	}
+	return &gacha, nil
+}
+
+// GetGachaItems は指定されたガチャに使用されるキャラクターとその確率を取得します。
+// 戻り値にはキャラクターのリスト(レアリティを含む)と全確率の合計が含まれます。
+// 存在しないキャラクターを参照している行も検証で検出できるよう、レアリティを 0 として返します。
+func (r *gachaRepository) GetGachaItems(gachaID int64) ([]model.GachaProbability, float64, error) {
+	rows, err := r.db.Query(`
+		SELECT gp.gacha_id, gp.character_id, gp.probability, COALESCE(c.rarity, 0)
+		FROM gacha_probabilities gp
+		LEFT JOIN characters c ON c.id = gp.character_id
+		WHERE gp.gacha_id = ?
+		ORDER BY gp.id
+	`, gachaID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -36,7 +166,7 @@ func (r *gachaRepository) GetGachaItems() ([]model.GachaProbability, float64, er
 	var totalProbability float64
 	for rows.Next() {
 		var item model.GachaProbability
-		if err := rows.Scan(&item.CharacterID, &item.Probability); err != nil {
+		if err := rows.Scan(&item.GachaID, &item.CharacterID, &item.Probability, &item.Rarity); err != nil {
 			return nil, 0, err
 		}
 		totalProbability += item.Probability
@@ -50,44 +180,655 @@ func (r *gachaRepository) GetGachaItems() ([]model.GachaProbability, float64, er
 	return items, totalProbability, nil
 }
 
-// AddUserCharacters はユーザーが取得したキャラクターを user_characters テーブルに追加します。
-func (r *gachaRepository) AddUserCharacters(userID int64, characterIDs []int64) error {
-	tx, err := r.db.Begin()
+// GetGachaGuarantees は指定されたガチャの確定枠ルールを取得します。
+func (r *gachaRepository) GetGachaGuarantees(gachaID int64) ([]model.GachaGuarantee, error) {
+	rows, err := r.db.Query(`
+		SELECT gacha_id, draw_count, min_rarity
+		FROM gacha_guarantees
+		WHERE gacha_id = ?
+		ORDER BY draw_count, min_rarity
+	`, gachaID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer tx.Rollback()
+	defer rows.Close()
 
-	stmt, err := tx.Prepare(`
-		INSERT INTO user_characters (user_id, character_id, acquired_at)
-		VALUES (?, ?, ?)
-	`)
+	var guarantees []model.GachaGuarantee
+	for rows.Next() {
+		var guarantee model.GachaGuarantee
+		if err := rows.Scan(&guarantee.GachaID, &guarantee.DrawCount, &guarantee.MinRarity); err != nil {
+			return nil, err
+		}
+		guarantees = append(guarantees, guarantee)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return guarantees, nil
+}
+
+// GetUserCoinForUpdate はガチャのコストを消費する前に、ユーザーのコイン残高をロックして取得します。
+func (r *gachaRepository) GetUserCoinForUpdate(userID int64) (int64, error) {
+	return getUserCoinForUpdate(r.db, userID)
+}
+
+// AddUserCoin はユーザーのコイン残高に delta を加算します。ガチャのコストは負の値で減算します。
+func (r *gachaRepository) AddUserCoin(userID, delta int64) error {
+	return addUserCoin(r.db, userID, delta)
+}
+
+// ReserveDrawRequest は冪等キーに対応するガチャ実行リクエストを確保し、行ロックを取得して返します。
+// 同じキーのリクエストが実行中の場合は、そのトランザクションが終了するまで待機します。
+// 既存の行は変更せずに挿入の時点で排他ロックを取得するため、同じキーのリクエストが同時に3件以上届いても、
+// 共有ロックから排他ロックへの昇格を待ち合ってデッドロックすることはありません。
+// トランザクション内で呼び出す必要があります。
+func (r *gachaRepository) ReserveDrawRequest(userID int64, key string, gachaID int64, times int) (*model.GachaDrawRequest, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO gacha_draw_requests (user_id, idempotency_key, gacha_id, times, created_at)
+		VALUES (?, ?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE idempotency_key = idempotency_key
+	`, userID, key, gachaID, times)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer stmt.Close()
 
-	currentTime := time.Now()
-	for _, cid := range characterIDs {
-		_, err := stmt.Exec(userID, cid, currentTime)
+	var req model.GachaDrawRequest
+	err = r.db.QueryRow(`
+		SELECT user_id, idempotency_key, gacha_id, times, response, created_at
+		FROM gacha_draw_requests
+		WHERE user_id = ? AND idempotency_key = ?
+		FOR UPDATE
+	`, userID, key).Scan(&req.UserID, &req.IdempotencyKey, &req.GachaID, &req.Times, &req.Response, &req.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// SaveDrawResponse は冪等キーに対応するガチャ実行リクエストの結果を保存します。
+func (r *gachaRepository) SaveDrawResponse(userID int64, key string, gachaID int64, times int, response []byte) error {
+	_, err := r.db.Exec(`
+		UPDATE gacha_draw_requests
+		SET gacha_id = ?, times = ?, response = ?, created_at = NOW()
+		WHERE user_id = ? AND idempotency_key = ?
+	`, gachaID, times, response, userID, key)
+	return err
+}
+
+// CreateDrawLog はガチャ実行の監査ログを gacha_draw_logs テーブルに追加し、追加した行のIDを返します。
+func (r *gachaRepository) CreateDrawLog(log *model.GachaDrawLog) (int64, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO gacha_draw_logs (batch_id, user_id, gacha_id, times, seed, results, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, NOW())
+	`, log.BatchID, log.UserID, log.GachaID, log.Times, log.Seed, log.Results)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetDrawLogs は指定されたユーザーのガチャ実行ログを新しい順に取得します。
+// beforeID が 0 より大きい場合は、そのIDより古いログのみを取得します。
+func (r *gachaRepository) GetDrawLogs(userID, beforeID int64, limit int) ([]model.GachaDrawLog, error) {
+	rows, err := r.db.Query(`
+		SELECT id, batch_id, user_id, gacha_id, times, seed, results, created_at
+		FROM gacha_draw_logs
+		WHERE user_id = ? AND (? = 0 OR id < ?)
+		ORDER BY id DESC
+		LIMIT ?
+	`, userID, beforeID, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []model.GachaDrawLog
+	for rows.Next() {
+		var log model.GachaDrawLog
+		if err := rows.Scan(&log.ID, &log.BatchID, &log.UserID, &log.GachaID, &log.Times, &log.Seed, &log.Results, &log.CreatedAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// AddUserCharacters はユーザーが取得したキャラクターを user_characters テーブルに追加し、追加した行のIDを返します。
+// トランザクション内で呼び出された場合は、そのトランザクションに参加します。
+func (r *gachaRepository) AddUserCharacters(userID int64, characterIDs []int64) ([]int64, error) {
+	userCharacterIDs := make([]int64, 0, len(characterIDs))
+	err := runInTx(r.db, func(tx dbtx) error {
+		stmt, err := tx.Prepare(`
+			INSERT INTO user_characters (user_id, character_id, acquired_at)
+			VALUES (?, ?, ?)
+		`)
 		if err != nil {
 			return err
 		}
+		defer stmt.Close()
+
+		currentTime := time.Now()
+		for _, cid := range characterIDs {
+			result, err := stmt.Exec(userID, cid, currentTime)
+			if err != nil {
+				return err
+			}
+			id, err := result.LastInsertId()
+			if err != nil {
+				return err
+			}
+			userCharacterIDs = append(userCharacterIDs, id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return userCharacterIDs, nil
+}
+
+// GetOwnedCharacters は指定されたユーザーが所持するキャラクターのうち、characterIDs に含まれるものを
+// キャラクターごとに最初に獲得した1行ずつ、キャラクターIDをキーとしたマップで取得します。
+// トランザクション内で呼び出された場合は、限界突破の同時更新を防ぐため行ロックを取得します。
+func (r *gachaRepository) GetOwnedCharacters(userID int64, characterIDs []int64) (map[int64]model.UserCharacter, error) {
+	owned := make(map[int64]model.UserCharacter)
+	if len(characterIDs) == 0 {
+		return owned, nil
+	}
+
+	args := []interface{}{userID}
+	for _, id := range characterIDs {
+		args = append(args, id)
+	}
+	rows, err := r.db.Query(`
+		SELECT id, user_id, character_id, limit_break, level, exp, locked, favorite, acquired_at
+		FROM user_characters
+		WHERE user_id = ? AND character_id IN (`+placeholders(len(characterIDs))+`)
+		ORDER BY id
+		FOR UPDATE
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var uc model.UserCharacter
+		if err := rows.Scan(&uc.ID, &uc.UserID, &uc.CharacterID, &uc.LimitBreak, &uc.Level, &uc.Exp, &uc.Locked, &uc.Favorite, &uc.AcquiredAt); err != nil {
+			return nil, err
+		}
+		if _, ok := owned[uc.CharacterID]; !ok {
+			owned[uc.CharacterID] = uc
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return tx.Commit()
+	return owned, nil
 }
 
-// GetCharacterName は指定されたキャラクターIDに対応するキャラクター名を取得します。
-func (r *gachaRepository) GetCharacterName(characterID int64) (string, error) {
-	var name string
+// AddLimitBreaks は所持キャラクターの限界突破レベルを、所持キャラクターIDごとに加算します。
+func (r *gachaRepository) AddLimitBreaks(increments map[int64]int) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		stmt, err := tx.Prepare(`
+			UPDATE user_characters
+			SET limit_break = limit_break + ?
+			WHERE id = ?
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for userCharacterID, n := range increments {
+			if _, err := stmt.Exec(n, userCharacterID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// AddUserItems は重複したキャラクターから変換したアイテムをユーザーの所持アイテムに加算します。
+func (r *gachaRepository) AddUserItems(userID int64, quantities map[int64]int64) error {
+	return addUserItems(r.db, userID, quantities)
+}
+
+// GetCharacters は抽選テーブルの検証と結果のキャラクター名の解決に使用する、すべてのキャラクターを取得します。
+func (r *gachaRepository) GetCharacters() ([]model.Character, error) {
+	return getCharacters(r.db)
+}
+
+// AddUserCollections はユーザーが獲得したキャラクターを図鑑に登録し、新たに登録したキャラクターIDを返します。
+// すでに登録済みのキャラクターは初回獲得日時を変更しません。
+func (r *gachaRepository) AddUserCollections(userID int64, characterIDs []int64) ([]int64, error) {
+	var added []int64
+	err := runInTx(r.db, func(tx dbtx) error {
+		stmt, err := tx.Prepare(`
+			INSERT IGNORE INTO user_collections (user_id, character_id, first_acquired_at)
+			VALUES (?, ?, NOW())
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, characterID := range characterIDs {
+			result, err := stmt.Exec(userID, characterID)
+			if err != nil {
+				return err
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				added = append(added, characterID)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// GetPitySettings は指定されたガチャのレアリティごとの天井設定を、レアリティの高い順に取得します。
+func (r *gachaRepository) GetPitySettings(gachaID int64) ([]model.PitySetting, error) {
+	rows, err := r.db.Query(`
+		SELECT gacha_id, rarity, hard_pity, soft_pity_start, soft_pity_step
+		FROM gacha_pity_settings
+		WHERE gacha_id = ?
+		ORDER BY rarity DESC
+	`, gachaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settings []model.PitySetting
+	for rows.Next() {
+		var setting model.PitySetting
+		if err := rows.Scan(&setting.GachaID, &setting.Rarity, &setting.HardPity, &setting.SoftPityStart, &setting.SoftPityStep); err != nil {
+			return nil, err
+		}
+		settings = append(settings, setting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// GetUserPities は指定されたユーザーの、指定されたガチャにおける天井カウンターを取得します。
+// トランザクション内で呼び出された場合は、同じユーザーの同時実行を防ぐため行ロックを取得します。
+func (r *gachaRepository) GetUserPities(userID, gachaID int64) ([]model.UserPity, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id, gacha_id, rarity, count, updated_at
+		FROM user_gacha_pities
+		WHERE user_id = ? AND gacha_id = ?
+		FOR UPDATE
+	`, userID, gachaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pities []model.UserPity
+	for rows.Next() {
+		var pity model.UserPity
+		if err := rows.Scan(&pity.UserID, &pity.GachaID, &pity.Rarity, &pity.Count, &pity.UpdatedAt); err != nil {
+			return nil, err
+		}
+		pities = append(pities, pity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pities, nil
+}
+
+// SaveUserPities は指定されたユーザーの、指定されたガチャにおける天井カウンターを保存します。
+func (r *gachaRepository) SaveUserPities(userID, gachaID int64, pities []model.UserPity) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		stmt, err := tx.Prepare(`
+			INSERT INTO user_gacha_pities (user_id, gacha_id, rarity, count, updated_at)
+			VALUES (?, ?, ?, ?, NOW())
+			ON DUPLICATE KEY UPDATE count = VALUES(count), updated_at = VALUES(updated_at)
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, pity := range pities {
+			if _, err := stmt.Exec(userID, gachaID, pity.Rarity, pity.Count); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetGachaSteps は指定されたステップアップガチャのステップ設定を、ステップの昇順に取得します。
+// ステップアップガチャでない場合は空のスライスを返します。
+func (r *gachaRepository) GetGachaSteps(gachaID int64) ([]model.GachaStep, error) {
+	rows, err := r.db.Query(`
+		SELECT gacha_id, step, times, cost, min_rarity, boost_rarity, boost_multiplier
+		FROM gacha_steps
+		WHERE gacha_id = ?
+		ORDER BY step
+	`, gachaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []model.GachaStep
+	for rows.Next() {
+		var step model.GachaStep
+		if err := rows.Scan(&step.GachaID, &step.Step, &step.Times, &step.Cost, &step.MinRarity, &step.BoostRarity, &step.BoostMultiplier); err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return steps, nil
+}
+
+// GetUserGachaStep は指定されたユーザーの、指定されたステップアップガチャの進行状況を取得します。
+// まだ一度も引いていない場合はステップ1の状態を返します。
+// トランザクション内で呼び出された場合は、同じユーザーの同時実行を防ぐため行ロックを取得します。
+func (r *gachaRepository) GetUserGachaStep(userID, gachaID int64) (*model.UserGachaStep, error) {
+	progress := model.UserGachaStep{UserID: userID, GachaID: gachaID, CurrentStep: 1}
 	err := r.db.QueryRow(`
-		SELECT name
-		FROM characters
-		WHERE id = ?
-	`, characterID).Scan(&name)
+		SELECT current_step, completed, updated_at
+		FROM user_gacha_steps
+		WHERE user_id = ? AND gacha_id = ?
+		FOR UPDATE
+	`, userID, gachaID).Scan(&progress.CurrentStep, &progress.Completed, &progress.UpdatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+// SaveUserGachaStep はユーザーのステップアップガチャの進行状況を保存します。
+func (r *gachaRepository) SaveUserGachaStep(progress *model.UserGachaStep) error {
+	_, err := r.db.Exec(`
+		INSERT INTO user_gacha_steps (user_id, gacha_id, current_step, completed, updated_at)
+		VALUES (?, ?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE current_step = VALUES(current_step), completed = VALUES(completed), updated_at = VALUES(updated_at)
+	`, progress.UserID, progress.GachaID, progress.CurrentStep, progress.Completed)
+	return err
+}
+
+// GetGachaBoxItems は指定された BOX ガチャの初期の中身を取得します。
+// BOX ガチャでない場合は空のスライスを返します。
+// 存在しないキャラクターを参照している行も検証で検出できるよう、レアリティを 0 として返します。
+func (r *gachaRepository) GetGachaBoxItems(gachaID int64) ([]model.GachaBoxItem, error) {
+	rows, err := r.db.Query(`
+		SELECT gb.gacha_id, gb.character_id, COALESCE(c.rarity, 0), gb.quantity, gb.featured
+		FROM gacha_box_items gb
+		LEFT JOIN characters c ON c.id = gb.character_id
+		WHERE gb.gacha_id = ?
+		ORDER BY gb.character_id
+	`, gachaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.GachaBoxItem
+	for rows.Next() {
+		var item model.GachaBoxItem
+		if err := rows.Scan(&item.GachaID, &item.CharacterID, &item.Rarity, &item.Quantity, &item.Featured); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// GetUserGachaBox は指定されたユーザーの、指定された BOX ガチャの現在の BOX を取得します。
+// まだ一度も引いていない場合は1個目の BOX の状態を返します。
+// トランザクション内で呼び出された場合は、同じユーザーの同時実行を防ぐため行ロックを取得します。
+func (r *gachaRepository) GetUserGachaBox(userID, gachaID int64) (*model.UserGachaBox, error) {
+	box := model.UserGachaBox{UserID: userID, GachaID: gachaID, BoxNumber: 1}
+	err := r.db.QueryRow(`
+		SELECT box_number, featured_drawn, updated_at
+		FROM user_gacha_boxes
+		WHERE user_id = ? AND gacha_id = ?
+		FOR UPDATE
+	`, userID, gachaID).Scan(&box.BoxNumber, &box.FeaturedDrawn, &box.UpdatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return &box, nil
+}
+
+// SaveUserGachaBox はユーザーの現在の BOX の状態を保存します。
+func (r *gachaRepository) SaveUserGachaBox(box *model.UserGachaBox) error {
+	_, err := r.db.Exec(`
+		INSERT INTO user_gacha_boxes (user_id, gacha_id, box_number, featured_drawn, updated_at)
+		VALUES (?, ?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE box_number = VALUES(box_number), featured_drawn = VALUES(featured_drawn), updated_at = VALUES(updated_at)
+	`, box.UserID, box.GachaID, box.BoxNumber, box.FeaturedDrawn)
+	return err
+}
+
+// GetUserGachaBoxDraws は指定されたユーザーの現在の BOX から、キャラクターごとに何個引いたかを取得します。
+// トランザクション内で呼び出された場合は、同じユーザーの同時実行を防ぐため行ロックを取得します。
+func (r *gachaRepository) GetUserGachaBoxDraws(userID, gachaID int64) (map[int64]int, error) {
+	rows, err := r.db.Query(`
+		SELECT character_id, drawn
+		FROM user_gacha_box_draws
+		WHERE user_id = ? AND gacha_id = ?
+		FOR UPDATE
+	`, userID, gachaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drawn := make(map[int64]int)
+	for rows.Next() {
+		var characterID int64
+		var count int
+		if err := rows.Scan(&characterID, &count); err != nil {
+			return nil, err
+		}
+		drawn[characterID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return drawn, nil
+}
+
+// AddUserGachaBoxDraws はユーザーの現在の BOX から引いた数を、キャラクターごとに加算します。
+func (r *gachaRepository) AddUserGachaBoxDraws(userID, gachaID int64, drawn map[int64]int) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		stmt, err := tx.Prepare(`
+			INSERT INTO user_gacha_box_draws (user_id, gacha_id, character_id, drawn)
+			VALUES (?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE drawn = drawn + VALUES(drawn)
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for characterID, count := range drawn {
+			if _, err := stmt.Exec(userID, gachaID, characterID, count); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ResetUserGachaBox はユーザーの BOX を初期状態に戻し、BOX の番号を1つ進めます。
+func (r *gachaRepository) ResetUserGachaBox(box *model.UserGachaBox) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		if _, err := tx.Exec(`
+			DELETE FROM user_gacha_box_draws
+			WHERE user_id = ? AND gacha_id = ?
+		`, box.UserID, box.GachaID); err != nil {
+			return err
+		}
+
+		box.BoxNumber++
+		box.FeaturedDrawn = false
+		return (&gachaRepository{tx}).SaveUserGachaBox(box)
+	})
+}
+
+// GetGachaRateUps は指定されたガチャのレアリティごとのピックアップ設定を、レアリティの高い順に取得します。
+// ピックアップキャラクターはキャラクターのレアリティの設定にまとめて返します。
+// レアリティの設定がないピックアップキャラクターも検証で検出できるよう、Share を 0 とした設定として返します。
+func (r *gachaRepository) GetGachaRateUps(gachaID int64) ([]model.GachaRateUp, error) {
+	rows, err := r.db.Query(`
+		SELECT gacha_id, rarity, share, guarantee
+		FROM gacha_rate_ups
+		WHERE gacha_id = ?
+		ORDER BY rarity DESC
+	`, gachaID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rateUps []model.GachaRateUp
+	index := make(map[int]int)
+	for rows.Next() {
+		var rateUp model.GachaRateUp
+		if err := rows.Scan(&rateUp.GachaID, &rateUp.Rarity, &rateUp.Share, &rateUp.Guarantee); err != nil {
+			return nil, err
+		}
+		index[rateUp.Rarity] = len(rateUps)
+		rateUps = append(rateUps, rateUp)
 	}
-	return name, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	characterRows, err := r.db.Query(`
+		SELECT rc.character_id, COALESCE(c.rarity, 0)
+		FROM gacha_rate_up_characters rc
+		LEFT JOIN characters c ON c.id = rc.character_id
+		WHERE rc.gacha_id = ?
+		ORDER BY rc.character_id
+	`, gachaID)
+	if err != nil {
+		return nil, err
+	}
+	defer characterRows.Close()
+
+	for characterRows.Next() {
+		var characterID int64
+		var rarity int
+		if err := characterRows.Scan(&characterID, &rarity); err != nil {
+			return nil, err
+		}
+		i, ok := index[rarity]
+		if !ok {
+			i = len(rateUps)
+			index[rarity] = i
+			rateUps = append(rateUps, model.GachaRateUp{GachaID: gachaID, Rarity: rarity})
+		}
+		rateUps[i].CharacterIDs = append(rateUps[i].CharacterIDs, characterID)
+	}
+	if err := characterRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rateUps, nil
+}
+
+// GetUserRateUps は指定されたユーザーの、指定されたガチャにおけるピックアップ確定の状態を取得します。
+// トランザクション内で呼び出された場合は、同じユーザーの同時実行を防ぐため行ロックを取得します。
+func (r *gachaRepository) GetUserRateUps(userID, gachaID int64) ([]model.UserGachaRateUp, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id, gacha_id, rarity, guaranteed, updated_at
+		FROM user_gacha_rate_ups
+		WHERE user_id = ? AND gacha_id = ?
+		FOR UPDATE
+	`, userID, gachaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rateUps []model.UserGachaRateUp
+	for rows.Next() {
+		var rateUp model.UserGachaRateUp
+		if err := rows.Scan(&rateUp.UserID, &rateUp.GachaID, &rateUp.Rarity, &rateUp.Guaranteed, &rateUp.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rateUps = append(rateUps, rateUp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rateUps, nil
+}
+
+// SaveUserRateUps は指定されたユーザーの、指定されたガチャにおけるピックアップ確定の状態を保存します。
+func (r *gachaRepository) SaveUserRateUps(userID, gachaID int64, rateUps []model.UserGachaRateUp) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		stmt, err := tx.Prepare(`
+			INSERT INTO user_gacha_rate_ups (user_id, gacha_id, rarity, guaranteed, updated_at)
+			VALUES (?, ?, ?, ?, NOW())
+			ON DUPLICATE KEY UPDATE guaranteed = VALUES(guaranteed), updated_at = VALUES(updated_at)
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, rateUp := range rateUps {
+			if _, err := stmt.Exec(userID, gachaID, rateUp.Rarity, rateUp.Guaranteed); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
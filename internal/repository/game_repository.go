@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"my-go-project/internal/model"
+)
+
+// GameRepository はゲームのスコアとランキング関連のデータベース操作を定義するインターフェースです。
+type GameRepository interface {
+	CreateGameScore(score *model.GameScore) error
+	SaveHighScore(userID, score int64) error
+	GetHighScore(userID int64) (*model.UserHighScore, error)
+	GetRanking(after *model.UserHighScore, limit int) ([]model.UserHighScore, error)
+	GetRankingEntry(position int) (*model.UserHighScore, error)
+	CountHigherScores(score int64) (int, error)
+	GetUserCoinForUpdate(userID int64) (int64, error)
+	AddUserCoin(userID, delta int64) error
+	Transaction(fn func(repo GameRepository) error) error
+}
+
+// gameRepository は GameRepository インターフェースを実装する構造体です。
+type gameRepository struct {
+	db dbtx
+}
+
+// NewGameRepository は新しい GameRepository を生成します。
+func NewGameRepository(db *sql.DB) GameRepository {
+	return &gameRepository{db}
+}
+
+// Transaction は fn を1つのトランザクション内で実行します。
+// fn に渡される GameRepository の操作はすべて同じトランザクションで行われ、fn がエラーを返した場合はロールバックされます。
+func (r *gameRepository) Transaction(fn func(repo GameRepository) error) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		return fn(&gameRepository{tx})
+	})
+}
+
+// CreateGameScore はゲームの結果を game_scores テーブルに追加します。
+func (r *gameRepository) CreateGameScore(score *model.GameScore) error {
+	result, err := r.db.Exec(`
+		INSERT INTO game_scores (user_id, score, coin, created_at)
+		VALUES (?, ?, ?, NOW())
+	`, score.UserID, score.Score, score.Coin)
+	if err != nil {
+		return err
+	}
+	score.ID, err = result.LastInsertId()
+	return err
+}
+
+// SaveHighScore は score がユーザーの最高スコアを上回る場合に最高スコアを更新します。
+// 同じスコアの場合は先に達成したユーザーを上位とするため、達成日時はスコアが上回った場合のみ更新します。
+func (r *gameRepository) SaveHighScore(userID, score int64) error {
+	// MySQL は ON DUPLICATE KEY UPDATE の代入を左から評価するため、achieved_at は更新前の score と比較する
+	_, err := r.db.Exec(`
+		INSERT INTO user_high_scores (user_id, score, achieved_at)
+		VALUES (?, ?, NOW())
+		ON DUPLICATE KEY UPDATE
+			achieved_at = IF(VALUES(score) > score, VALUES(achieved_at), achieved_at),
+			score = GREATEST(score, VALUES(score))
+	`, userID, score)
+	return err
+}
+
+// GetHighScore は指定されたユーザーの最高スコアを取得します。まだゲームを終了したことがない場合は nil を返します。
+func (r *gameRepository) GetHighScore(userID int64) (*model.UserHighScore, error) {
+	var hs model.UserHighScore
+	err := r.db.QueryRow(`
+		SELECT hs.user_id, u.name, hs.score, hs.achieved_at
+		FROM user_high_scores hs
+		JOIN users u ON u.id = hs.user_id
+		WHERE hs.user_id = ?
+	`, userID).Scan(&hs.UserID, &hs.Name, &hs.Score, &hs.AchievedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hs, nil
+}
+
+// GetRanking は最高スコアの降順(同じスコアは達成日時、ユーザーIDの昇順)で limit 件を取得します。
+// after が指定された場合はその行より後ろの行のみを取得します。
+// 並び順どおりのインデックスで after の位置から走査するため、ページの位置に関係なく取得する行数に比例する計算量で済みます。
+func (r *gameRepository) GetRanking(after *model.UserHighScore, limit int) ([]model.UserHighScore, error) {
+	where := "TRUE"
+	var args []interface{}
+	if after != nil {
+		where = "(hs.score < ? OR (hs.score = ? AND (hs.achieved_at > ? OR (hs.achieved_at = ? AND hs.user_id > ?))))"
+		args = append(args, after.Score, after.Score, after.AchievedAt, after.AchievedAt, after.UserID)
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.Query(`
+		SELECT hs.user_id, u.name, hs.score, hs.achieved_at
+		FROM user_high_scores hs
+		JOIN users u ON u.id = hs.user_id
+		WHERE `+where+`
+		ORDER BY hs.score DESC, hs.achieved_at, hs.user_id
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ranking []model.UserHighScore
+	for rows.Next() {
+		var hs model.UserHighScore
+		if err := rows.Scan(&hs.UserID, &hs.Name, &hs.Score, &hs.AchievedAt); err != nil {
+			return nil, err
+		}
+		ranking = append(ranking, hs)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ranking, nil
+}
+
+// GetRankingEntry はランキングの position 番目(1始まり)の行の並び順のキーを取得します。行が存在しない場合は nil を返します。
+// 並び順どおりのインデックスのみを走査し、users テーブルとの結合は行いません(Name は空です)。
+func (r *gameRepository) GetRankingEntry(position int) (*model.UserHighScore, error) {
+	var hs model.UserHighScore
+	err := r.db.QueryRow(`
+		SELECT user_id, score, achieved_at
+		FROM user_high_scores
+		ORDER BY score DESC, achieved_at, user_id
+		LIMIT 1 OFFSET ?
+	`, position-1).Scan(&hs.UserID, &hs.Score, &hs.AchievedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hs, nil
+}
+
+// CountHigherScores は最高スコアが score より高いユーザーの数を取得します。
+// ランキングのインデックスの score の範囲のみを数えます。
+func (r *gameRepository) CountHigherScores(score int64) (int, error) {
+	var n int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM user_high_scores
+		WHERE score > ?
+	`, score).Scan(&n)
+	return n, err
+}
+
+// GetUserCoinForUpdate はスコアに応じたコインを加算する前に、ユーザーのコイン残高をロックして取得します。
+func (r *gameRepository) GetUserCoinForUpdate(userID int64) (int64, error) {
+	return getUserCoinForUpdate(r.db, userID)
+}
+
+// AddUserCoin はスコアに応じて獲得したコインをユーザーのコイン残高に加算します。
+func (r *gameRepository) AddUserCoin(userID, delta int64) error {
+	return addUserCoin(r.db, userID, delta)
+}
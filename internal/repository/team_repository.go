@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"my-go-project/internal/model"
+)
+
+// TeamRepository はチーム編成関連のデータベース操作を定義するインターフェースです。
+type TeamRepository interface {
+	GetTeams(userID int64) ([]model.Team, error)
+	GetTeam(userID, teamID int64) (*model.Team, error)
+	CreateTeam(userID int64, name string) (int64, error)
+	UpdateTeamName(teamID int64, name string) error
+	SaveTeamMembers(teamID int64, userCharacterIDs []int64) error
+	DeleteTeam(userID, teamID int64) (bool, error)
+	GetUserCharactersByIDs(userID int64, userCharacterIDs []int64) (map[int64]model.UserCharacter, error)
+	Transaction(fn func(repo TeamRepository) error) error
+}
+
+// teamRepository は TeamRepository インターフェースを実装する構造体です。
+type teamRepository struct {
+	db dbtx
+}
+
+// NewTeamRepository は新しい TeamRepository を生成します。
+func NewTeamRepository(db *sql.DB) TeamRepository {
+	return &teamRepository{db}
+}
+
+// Transaction は fn を1つのトランザクション内で実行します。
+// fn に渡される TeamRepository の操作はすべて同じトランザクションで行われ、fn がエラーを返した場合はロールバックされます。
+func (r *teamRepository) Transaction(fn func(repo TeamRepository) error) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		return fn(&teamRepository{tx})
+	})
+}
+
+// GetTeams は指定されたユーザーのチームを、メンバーを含めてチームIDの昇順に取得します。
+// トランザクション内で呼び出された場合は、チーム数の確認と作成の同時実行を防ぐため行ロックを取得します。
+func (r *teamRepository) GetTeams(userID int64) ([]model.Team, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, name, created_at, updated_at
+		FROM teams
+		WHERE user_id = ?
+		ORDER BY id
+		FOR UPDATE
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []model.Team
+	index := make(map[int64]int)
+	for rows.Next() {
+		var team model.Team
+		if err := rows.Scan(&team.ID, &team.UserID, &team.Name, &team.CreatedAt, &team.UpdatedAt); err != nil {
+			return nil, err
+		}
+		index[team.ID] = len(teams)
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	members, err := r.getTeamMembers(userID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		if i, ok := index[member.TeamID]; ok {
+			teams[i].Members = append(teams[i].Members, member)
+		}
+	}
+
+	return teams, nil
+}
+
+// GetTeam は指定されたユーザーのチームを、メンバーを含めて取得します。
+// チームが存在しないか、他のユーザーのチームの場合は nil を返します。
+// トランザクション内で呼び出された場合は、チームの同時更新を防ぐため行ロックを取得します。
+func (r *teamRepository) GetTeam(userID, teamID int64) (*model.Team, error) {
+	var team model.Team
+	err := r.db.QueryRow(`
+		SELECT id, user_id, name, created_at, updated_at
+		FROM teams
+		WHERE id = ? AND user_id = ?
+		FOR UPDATE
+	`, teamID, userID).Scan(&team.ID, &team.UserID, &team.Name, &team.CreatedAt, &team.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := r.getTeamMembers(userID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	team.Members = members
+
+	return &team, nil
+}
+
+// getTeamMembers は指定されたユーザーのチームのメンバーを、チームIDとスロットの昇順に取得します。
+// teamID が 0 の場合はユーザーのすべてのチームのメンバーを取得します。
+func (r *teamRepository) getTeamMembers(userID, teamID int64) ([]model.TeamMember, error) {
+	rows, err := r.db.Query(`
+		SELECT tm.team_id, tm.slot, tm.user_character_id
+		FROM team_members tm
+		JOIN teams t ON t.id = tm.team_id
+		WHERE t.user_id = ? AND (? = 0 OR t.id = ?)
+		ORDER BY tm.team_id, tm.slot
+	`, userID, teamID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []model.TeamMember
+	for rows.Next() {
+		var member model.TeamMember
+		if err := rows.Scan(&member.TeamID, &member.Slot, &member.UserCharacterID); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// CreateTeam は新しいチームを作成し、作成したチームのIDを返します。メンバーは SaveTeamMembers で保存します。
+func (r *teamRepository) CreateTeam(userID int64, name string) (int64, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO teams (user_id, name, created_at, updated_at)
+		VALUES (?, ?, NOW(), NOW())
+	`, userID, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateTeamName はチームの名前を更新します。
+func (r *teamRepository) UpdateTeamName(teamID int64, name string) error {
+	_, err := r.db.Exec(`
+		UPDATE teams
+		SET name = ?, updated_at = NOW()
+		WHERE id = ?
+	`, name, teamID)
+	return err
+}
+
+// SaveTeamMembers はチームのメンバーを userCharacterIDs の順にスロット1から置き換えます。
+func (r *teamRepository) SaveTeamMembers(teamID int64, userCharacterIDs []int64) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		if _, err := tx.Exec(`DELETE FROM team_members WHERE team_id = ?`, teamID); err != nil {
+			return err
+		}
+
+		stmt, err := tx.Prepare(`
+			INSERT INTO team_members (team_id, slot, user_character_id)
+			VALUES (?, ?, ?)
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for i, userCharacterID := range userCharacterIDs {
+			if _, err := stmt.Exec(teamID, i+1, userCharacterID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteTeam は指定されたユーザーのチームを削除します。メンバーは外部キーの ON DELETE CASCADE により削除されます。
+// 削除したかどうかを返し、チームが存在しないか他のユーザーのチームの場合は false を返します。
+func (r *teamRepository) DeleteTeam(userID, teamID int64) (bool, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM teams
+		WHERE id = ? AND user_id = ?
+	`, teamID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// GetUserCharactersByIDs は編成する所持キャラクターを、指定されたユーザーが所持しているものに限って取得します。
+// 編成の保存中に売却や育成の素材として消費されないよう、トランザクション内では行ロックを取得します。
+func (r *teamRepository) GetUserCharactersByIDs(userID int64, userCharacterIDs []int64) (map[int64]model.UserCharacter, error) {
+	return getUserCharactersByIDs(r.db, userID, userCharacterIDs)
+}
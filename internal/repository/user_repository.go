@@ -51,10 +51,10 @@ func (r *userRepository) CreateUser(name string) (*model.User, error) {
 func (r *userRepository) GetUserByID(id int64) (*model.User, error) {
 	var user model.User
 	err := r.db.QueryRow(`
-		SELECT id, name, created_at, updated_at
+		SELECT id, name, coin, created_at, updated_at
 		FROM users
 		WHERE id = ?
-	`, id).Scan(&user.ID, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+	`, id).Scan(&user.ID, &user.Name, &user.Coin, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -70,3 +70,31 @@ func (r *userRepository) UpdateUser(id int64, name string) error {
 	`, name, id)
 	return err
 }
+
+// getUserCoinForUpdate は指定されたユーザーのコイン残高を取得します。
+// トランザクション内で呼び出された場合は、残高の更新が終わるまで他のトランザクションからの更新を防ぐため行ロックを取得します。
+// コインを増減する各リポジトリの GetUserCoinForUpdate から呼び出します。
+func getUserCoinForUpdate(db dbtx, userID int64) (int64, error) {
+	var coin int64
+	err := db.QueryRow(`
+		SELECT coin
+		FROM users
+		WHERE id = ?
+		FOR UPDATE
+	`, userID).Scan(&coin)
+	if err != nil {
+		return 0, err
+	}
+	return coin, nil
+}
+
+// addUserCoin は指定されたユーザーのコイン残高に delta を加算します。負の値を指定すると減算します。
+// コインを増減する各リポジトリの AddUserCoin から呼び出します。
+func addUserCoin(db dbtx, userID, delta int64) error {
+	_, err := db.Exec(`
+		UPDATE users
+		SET coin = coin + ?, updated_at = NOW()
+		WHERE id = ?
+	`, delta, userID)
+	return err
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"my-go-project/internal/model"
+)
+
+// CharacterCache はキャラクターのマスターデータをメモリ上にキャッシュします。
+// ガチャ結果や提供割合でキャラクター名を解決する際に、キャラクターごとにクエリを発行しないために使用します。
+// キャラクターのマスターデータを参照するサービスで1つのインスタンスを共有し、Invalidate で一度に破棄できるようにします。
+type CharacterCache struct {
+	mu         sync.RWMutex
+	characters map[int64]model.Character
+	loadedAt   time.Time
+	ttl        time.Duration
+}
+
+// NewCharacterCache は新しい CharacterCache を生成します。キャッシュの期間はガチャのマスターデータと同じ masterCacheTTL です。
+func NewCharacterCache() *CharacterCache {
+	return &CharacterCache{ttl: masterCacheTTL}
+}
+
+// characterSource はキャラクターのマスターデータを読み込むリポジトリです。
+// キャラクターのマスターデータを参照する各サービスのリポジトリから読み込めるようにするために使用します。
+type characterSource interface {
+	GetCharacters() ([]model.Character, error)
+}
+
+// get はすべてのキャラクターをIDをキーとしたマップで返します。
+// キャッシュが空の場合や期限切れの場合は repo から1回のクエリで読み込みます。
+func (c *CharacterCache) get(repo characterSource) (map[int64]model.Character, error) {
+	c.mu.RLock()
+	characters, loadedAt := c.characters, c.loadedAt
+	c.mu.RUnlock()
+	if characters != nil && time.Since(loadedAt) < c.ttl {
+		return characters, nil
+	}
+
+	list, err := repo.GetCharacters()
+	if err != nil {
+		return nil, err
+	}
+	characters = charactersByID(list)
+
+	c.mu.Lock()
+	c.characters = characters
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+
+	return characters, nil
+}
+
+// Invalidate はキャッシュしているキャラクターを破棄します。
+// キャラクターのマスターデータを更新した後に呼び出します。
+func (c *CharacterCache) Invalidate() {
+	c.mu.Lock()
+	c.characters = nil
+	c.mu.Unlock()
+}
+
+// charactersByID はキャラクターのリストをIDをキーとしたマップに変換します。
+func charactersByID(list []model.Character) map[int64]model.Character {
+	characters := make(map[int64]model.Character, len(list))
+	for _, character := range list {
+		characters[character.ID] = character
+	}
+	return characters
+}
+
+// characterName はキャラクターIDに対応するキャラクター名を返します。見つからない場合は "Unknown" を返します。
+func characterName(characters map[int64]model.Character, characterID int64) string {
+	if character, ok := characters[characterID]; ok {
+		return character.Name
+	}
+	return "Unknown"
+}
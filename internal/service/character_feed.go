@@ -0,0 +1,194 @@
+package service
+
+import (
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+// MaxFeedCharacters は1回の育成で消費できる所持キャラクターの最大数です。
+const MaxFeedCharacters = 100
+
+// FeedMaterial は育成素材として消費するアイテムとその個数を表す構造体です。
+type FeedMaterial struct {
+	ItemID   int64 `json:"itemID"`
+	Quantity int64 `json:"quantity"`
+}
+
+// FeedRequest は所持キャラクターの育成内容を表す構造体です。
+// UserCharacterID の所持キャラクターに、Materials のアイテムと UserCharacterIDs の所持キャラクターを消費して経験値を与えます。
+type FeedRequest struct {
+	UserCharacterID  int64          `json:"userCharacterID"`
+	Materials        []FeedMaterial `json:"materials"`
+	UserCharacterIDs []int64        `json:"userCharacterIDs"`
+}
+
+// FeedResult は育成の結果を表す構造体です。
+// MaxLevel はキャラクターのレアリティのレベル上限で、上限に達した時点で余った経験値は切り捨てられます。
+// NextLevelExp は次のレベルに必要な累計経験値で、レベル上限に達している場合は 0 です。
+type FeedResult struct {
+	Character     UserCharacterResponse `json:"character"`
+	PreviousLevel int                   `json:"previousLevel"`
+	GainedExp     int64                 `json:"gainedExp"`
+	MaxLevel      int                   `json:"maxLevel"`
+	NextLevelExp  int64                 `json:"nextLevelExp"`
+}
+
+// FeedCharacter は所持キャラクターにアイテムや他の所持キャラクターを消費して経験値を与え、レベルを上げます。
+// 所持キャラクターとアイテムの確認、消費、レベルの更新は1つのトランザクション内で行います。
+// 育成対象または消費するキャラクターがユーザーの所持キャラクターでない場合は ErrCharacterNotOwned を、
+// 消費するキャラクターがロックされている場合は ErrCharacterLocked を、チームに編成されている場合は ErrCharacterInTeam を返します。
+func (s *characterService) FeedCharacter(userID int64, req FeedRequest) (*FeedResult, error) {
+	quantities, err := feedQuantities(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *FeedResult
+	err = s.repo.Transaction(func(repo repository.CharacterRepository) error {
+		ids := append([]int64{req.UserCharacterID}, req.UserCharacterIDs...)
+		owned, err := repo.GetUserCharactersByIDs(userID, ids)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if _, ok := owned[id]; !ok {
+				return ErrCharacterNotOwned
+			}
+		}
+		for _, id := range req.UserCharacterIDs {
+			if owned[id].Locked {
+				return ErrCharacterLocked
+			}
+		}
+		if err := checkTeamAssigned(repo, req.UserCharacterIDs); err != nil {
+			return err
+		}
+		target := owned[req.UserCharacterID]
+
+		curve, err := repo.GetLevelCurve(target.Rarity)
+		if err != nil {
+			return err
+		}
+		if len(curve) == 0 {
+			return ErrInvalidFeed
+		}
+		maxLevel := curve[len(curve)-1]
+		if target.Exp >= maxLevel.RequiredExp {
+			return ErrMaxLevel
+		}
+
+		// 消費するアイテムの所持数と経験値を確認する
+		var gained int64
+		if len(quantities) > 0 {
+			itemIDs := make([]int64, 0, len(quantities))
+			for itemID := range quantities {
+				itemIDs = append(itemIDs, itemID)
+			}
+			items, err := repo.GetUserItemsByIDs(userID, itemIDs)
+			if err != nil {
+				return err
+			}
+			consumed := make(map[int64]int64, len(quantities))
+			for itemID, quantity := range quantities {
+				item, ok := items[itemID]
+				if !ok || item.Quantity < quantity {
+					return ErrInsufficientItems
+				}
+				if item.Exp <= 0 {
+					return ErrInvalidFeed
+				}
+				gained += item.Exp * quantity
+				consumed[itemID] = -quantity
+			}
+			if err := repo.AddUserItems(userID, consumed); err != nil {
+				return err
+			}
+		}
+
+		// 消費するキャラクターの経験値はレアリティごとの設定に従う
+		if len(req.UserCharacterIDs) > 0 {
+			feedExps, err := repo.GetFeedExps()
+			if err != nil {
+				return err
+			}
+			exps := make(map[int]int64, len(feedExps))
+			for _, exp := range feedExps {
+				exps[exp.Rarity] = exp.Exp
+			}
+			for _, id := range req.UserCharacterIDs {
+				gained += exps[owned[id].Rarity]
+			}
+			if err := repo.DeleteUserCharacters(userID, req.UserCharacterIDs); err != nil {
+				return err
+			}
+		}
+
+		exp := target.Exp + gained
+		if exp > maxLevel.RequiredExp {
+			exp = maxLevel.RequiredExp
+		}
+		level, next := levelForExp(curve, exp)
+		if err := repo.UpdateUserCharacterLevel(target.ID, level, exp); err != nil {
+			return err
+		}
+
+		fed := target
+		fed.Level, fed.Exp = level, exp
+		result = &FeedResult{
+			Character:     userCharacterResponse(fed),
+			PreviousLevel: target.Level,
+			GainedExp:     exp - target.Exp,
+			MaxLevel:      maxLevel.Level,
+			NextLevelExp:  next,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// feedQuantities は育成内容を検証し、消費するアイテムの個数をアイテムIDごとに合計します。
+// 何も消費しない場合、消費するキャラクターが MaxFeedCharacters を超える場合、個数が正でない場合、
+// 所持キャラクターIDが重複している場合や育成対象自身を消費する場合は ErrInvalidFeed を返します。
+func feedQuantities(req FeedRequest) (map[int64]int64, error) {
+	if req.UserCharacterID <= 0 || (len(req.Materials) == 0 && len(req.UserCharacterIDs) == 0) {
+		return nil, ErrInvalidFeed
+	}
+	if len(req.UserCharacterIDs) > MaxFeedCharacters {
+		return nil, ErrInvalidFeed
+	}
+
+	seen := map[int64]bool{req.UserCharacterID: true}
+	for _, id := range req.UserCharacterIDs {
+		if id <= 0 || seen[id] {
+			return nil, ErrInvalidFeed
+		}
+		seen[id] = true
+	}
+
+	quantities := make(map[int64]int64, len(req.Materials))
+	for _, material := range req.Materials {
+		if material.ItemID <= 0 || material.Quantity <= 0 {
+			return nil, ErrInvalidFeed
+		}
+		quantities[material.ItemID] += material.Quantity
+	}
+
+	return quantities, nil
+}
+
+// levelForExp は累計経験値 exp に対応するレベルと、次のレベルに必要な累計経験値を返します。
+// curve はレベルの昇順に並んでいる必要があり、レベル上限に達している場合の次のレベルの経験値は 0 です。
+func levelForExp(curve []model.LevelCurve, exp int64) (int, int64) {
+	level := 1
+	for i, c := range curve {
+		if exp < c.RequiredExp {
+			return level, curve[i].RequiredExp
+		}
+		level = c.Level
+	}
+	return level, 0
+}
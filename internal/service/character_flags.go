@@ -0,0 +1,67 @@
+package service
+
+import (
+	"my-go-project/internal/repository"
+)
+
+// MaxCharacterFlagsUpdate は1回のロック・お気に入りの更新で指定できる所持キャラクターの最大数です。
+const MaxCharacterFlagsUpdate = 100
+
+// CharacterFlagsRequest は所持キャラクターのロックとお気に入りの更新内容を表す構造体です。
+// Locked と Favorite は省略した場合(nil の場合)は変更しません。
+type CharacterFlagsRequest struct {
+	UserCharacterIDs []int64 `json:"userCharacterIDs"`
+	Locked           *bool   `json:"locked"`
+	Favorite         *bool   `json:"favorite"`
+}
+
+// UpdateCharacterFlags は指定された所持キャラクターのロックとお気に入りをまとめて更新し、更新後の所持キャラクターを返します。
+// 1体でもユーザーの所持キャラクターでない場合は ErrCharacterNotOwned を返し、何も更新しません。
+func (s *characterService) UpdateCharacterFlags(userID int64, req CharacterFlagsRequest) ([]UserCharacterResponse, error) {
+	if len(req.UserCharacterIDs) == 0 || len(req.UserCharacterIDs) > MaxCharacterFlagsUpdate ||
+		(req.Locked == nil && req.Favorite == nil) {
+		return nil, ErrInvalidCharacterFlags
+	}
+	seen := make(map[int64]bool, len(req.UserCharacterIDs))
+	for _, id := range req.UserCharacterIDs {
+		if id <= 0 || seen[id] {
+			return nil, ErrInvalidCharacterFlags
+		}
+		seen[id] = true
+	}
+
+	var updated []UserCharacterResponse
+	err := s.repo.Transaction(func(repo repository.CharacterRepository) error {
+		owned, err := repo.GetUserCharactersByIDs(userID, req.UserCharacterIDs)
+		if err != nil {
+			return err
+		}
+		for _, id := range req.UserCharacterIDs {
+			if _, ok := owned[id]; !ok {
+				return ErrCharacterNotOwned
+			}
+		}
+
+		if err := repo.UpdateUserCharacterFlags(userID, req.UserCharacterIDs, req.Locked, req.Favorite); err != nil {
+			return err
+		}
+
+		updated = make([]UserCharacterResponse, 0, len(req.UserCharacterIDs))
+		for _, id := range req.UserCharacterIDs {
+			uc := owned[id]
+			if req.Locked != nil {
+				uc.Locked = *req.Locked
+			}
+			if req.Favorite != nil {
+				uc.Favorite = *req.Favorite
+			}
+			updated = append(updated, userCharacterResponse(uc))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
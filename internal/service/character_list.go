@@ -0,0 +1,177 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"my-go-project/internal/model"
+)
+
+const (
+	// DefaultCharacterListLimit は所持キャラクター一覧の取得時に件数が指定されなかった場合の取得件数です。
+	DefaultCharacterListLimit = 100
+	// MaxCharacterListLimit は所持キャラクター一覧を1回で取得できる最大件数です。
+	MaxCharacterListLimit = 500
+)
+
+// 所持キャラクター一覧の並び順です。
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// CharacterListQuery は所持キャラクター一覧の取得条件を表す構造体です。
+// Sort は acquired_at / rarity / name のいずれか、Order は asc / desc のいずれかで、省略した場合は獲得日時の昇順です。
+// Rarity と CharacterID は 0 より大きい場合のみ、Locked と Favorite は nil でない場合のみ絞り込みに使用します。
+// Cursor には前のページの NextCursor を指定し、空の場合は先頭から取得します。
+type CharacterListQuery struct {
+	Cursor      string
+	Limit       int
+	Sort        string
+	Order       string
+	Rarity      int
+	CharacterID int64
+	Locked      *bool
+	Favorite    *bool
+}
+
+// CharacterList は所持キャラクター一覧の1ページを表す構造体です。
+// NextCursor は次のページを取得するためのカーソルで、次のページがない場合は空文字列です。
+type CharacterList struct {
+	Characters []UserCharacterResponse `json:"characters"`
+	NextCursor string                  `json:"nextCursor"`
+}
+
+// characterCursor はカーソルに埋め込む、ページの最後の行の並び替えキーです。
+// 並び替えの条件が異なるカーソルを受け付けないよう、Sort と Desc も含めます。
+type characterCursor struct {
+	Sort       string    `json:"s"`
+	Desc       bool      `json:"d,omitempty"`
+	ID         int64     `json:"id"`
+	AcquiredAt time.Time `json:"a,omitempty"`
+	Rarity     int       `json:"r,omitempty"`
+	Name       string    `json:"n,omitempty"`
+}
+
+// ListCharacters は指定されたユーザーが所持するキャラクターの一覧を、指定された条件で1ページ分取得します。
+// 並び替えと絞り込みはリポジトリのクエリで行い、ページングは並び替えキーと所持キャラクターIDによるカーソル方式です。
+func (s *characterService) ListCharacters(userID int64, query CharacterListQuery) (*CharacterList, error) {
+	q := model.UserCharacterQuery{
+		UserID:      userID,
+		Rarity:      query.Rarity,
+		CharacterID: query.CharacterID,
+		Locked:      query.Locked,
+		Favorite:    query.Favorite,
+		Sort:        query.Sort,
+		Limit:       query.Limit,
+	}
+	switch q.Sort {
+	case "":
+		q.Sort = model.UserCharacterSortAcquiredAt
+	case model.UserCharacterSortAcquiredAt, model.UserCharacterSortRarity, model.UserCharacterSortName:
+	default:
+		return nil, ErrInvalidListQuery
+	}
+	switch query.Order {
+	case "", OrderAsc:
+	case OrderDesc:
+		q.Desc = true
+	default:
+		return nil, ErrInvalidListQuery
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultCharacterListLimit
+	}
+	if q.Limit > MaxCharacterListLimit {
+		q.Limit = MaxCharacterListLimit
+	}
+
+	if query.Cursor != "" {
+		after, err := decodeCharacterCursor(query.Cursor, q.Sort, q.Desc)
+		if err != nil {
+			return nil, err
+		}
+		q.After = after
+	}
+
+	// 次のページの有無を判定するため1件多く取得する
+	limit := q.Limit
+	q.Limit++
+	userCharacters, err := s.repo.GetUserCharacters(q)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &CharacterList{Characters: make([]UserCharacterResponse, 0, limit)}
+	if len(userCharacters) > limit {
+		userCharacters = userCharacters[:limit]
+		cursor, err := encodeCharacterCursor(userCharacters[limit-1], q.Sort, q.Desc)
+		if err != nil {
+			return nil, err
+		}
+		list.NextCursor = cursor
+	}
+
+	for _, uc := range userCharacters {
+		list.Characters = append(list.Characters, userCharacterResponse(uc))
+	}
+
+	return list, nil
+}
+
+// userCharacterResponse は所持キャラクターをレスポンス用に変換します。
+func userCharacterResponse(uc model.UserCharacter) UserCharacterResponse {
+	return UserCharacterResponse{
+		UserCharacterID: uc.ID,
+		CharacterID:     uc.CharacterID,
+		Name:            uc.Name,
+		Rarity:          uc.Rarity,
+		LimitBreak:      uc.LimitBreak,
+		Level:           uc.Level,
+		Exp:             uc.Exp,
+		Locked:          uc.Locked,
+		Favorite:        uc.Favorite,
+		AcquiredAt:      uc.AcquiredAt,
+	}
+}
+
+// encodeCharacterCursor は所持キャラクター uc の次の行から取得するためのカーソルを生成します。
+func encodeCharacterCursor(uc model.UserCharacter, sort string, desc bool) (string, error) {
+	cursor := characterCursor{Sort: sort, Desc: desc, ID: uc.ID}
+	switch sort {
+	case model.UserCharacterSortAcquiredAt:
+		cursor.AcquiredAt = uc.AcquiredAt
+	case model.UserCharacterSortRarity:
+		cursor.Rarity = uc.Rarity
+	case model.UserCharacterSortName:
+		cursor.Name = uc.Name
+	}
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCharacterCursor はカーソルを解析し、前のページの最後の行を返します。
+// カーソルが不正な場合や、並び替えの条件がカーソルの生成時と異なる場合は ErrInvalidCursor を返します。
+func decodeCharacterCursor(value, sort string, desc bool) (*model.UserCharacter, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor characterCursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != sort || cursor.Desc != desc || cursor.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &model.UserCharacter{
+		ID:         cursor.ID,
+		AcquiredAt: cursor.AcquiredAt,
+		Rarity:     cursor.Rarity,
+		Name:       cursor.Name,
+	}, nil
+}
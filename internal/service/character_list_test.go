@@ -0,0 +1,210 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+// countingCharacterRepository は所持キャラクター一覧とキャラクターのマスターデータを返し、それぞれのクエリ回数を数える CharacterRepository です。
+// 所持キャラクターはすべてユーザーID 1 のもので、一覧は獲得日時(所持キャラクターIDと同じ順)の昇順のみ対応します。
+// 一覧の取得で使用しない育成、売却、アイテム関連のメソッドは、空の結果を返すか何もしません。
+type countingCharacterRepository struct {
+	userCharacters       []model.UserCharacter
+	characters           []model.Character
+	userCharacterQueries int
+	characterQueries     int
+}
+
+func (r *countingCharacterRepository) GetUserCharacters(query model.UserCharacterQuery) ([]model.UserCharacter, error) {
+	r.userCharacterQueries++
+
+	var rows []model.UserCharacter
+	for _, uc := range r.userCharacters {
+		if query.After != nil && uc.ID <= query.After.ID {
+			continue
+		}
+		if len(rows) == query.Limit {
+			break
+		}
+		rows = append(rows, uc)
+	}
+	return rows, nil
+}
+
+func (r *countingCharacterRepository) GetCharacters() ([]model.Character, error) {
+	r.characterQueries++
+	return r.characters, nil
+}
+
+func (r *countingCharacterRepository) GetUserCharactersByIDs(userID int64, userCharacterIDs []int64) (map[int64]model.UserCharacter, error) {
+	found := make(map[int64]model.UserCharacter)
+	for _, id := range userCharacterIDs {
+		if id > 0 && int(id) <= len(r.userCharacters) {
+			found[id] = r.userCharacters[id-1]
+		}
+	}
+	return found, nil
+}
+
+func (r *countingCharacterRepository) DeleteUserCharacters(userID int64, userCharacterIDs []int64) error {
+	return nil
+}
+
+func (r *countingCharacterRepository) UpdateUserCharacterLevel(userCharacterID int64, level int, exp int64) error {
+	return nil
+}
+
+func (r *countingCharacterRepository) UpdateUserCharacterFlags(userID int64, userCharacterIDs []int64, locked, favorite *bool) error {
+	return nil
+}
+
+func (r *countingCharacterRepository) GetTeamAssignedCharacters(userCharacterIDs []int64) (map[int64]bool, error) {
+	return map[int64]bool{}, nil
+}
+
+func (r *countingCharacterRepository) GetUserItems(userID int64) ([]model.UserItem, error) {
+	return nil, nil
+}
+
+func (r *countingCharacterRepository) GetUserItemsByIDs(userID int64, itemIDs []int64) (map[int64]model.UserItem, error) {
+	return map[int64]model.UserItem{}, nil
+}
+
+func (r *countingCharacterRepository) AddUserItems(userID int64, quantities map[int64]int64) error {
+	return nil
+}
+
+func (r *countingCharacterRepository) GetLevelCurve(rarity int) ([]model.LevelCurve, error) {
+	return nil, nil
+}
+
+func (r *countingCharacterRepository) GetFeedExps() ([]model.FeedExp, error) {
+	return nil, nil
+}
+
+func (r *countingCharacterRepository) GetSellRewards() ([]model.SellReward, error) {
+	return nil, nil
+}
+
+func (r *countingCharacterRepository) GetUserCoinForUpdate(userID int64) (int64, error) {
+	return 0, nil
+}
+
+func (r *countingCharacterRepository) AddUserCoin(userID, delta int64) error {
+	return nil
+}
+
+func (r *countingCharacterRepository) Transaction(fn func(repo repository.CharacterRepository) error) error {
+	return fn(r)
+}
+
+// newCountingCharacterRepository は n 体の所持キャラクターを持つ countingCharacterRepository を返します。
+// 所持キャラクターのキャラクター名とレアリティは GetUserCharacters の結合で取得済みの状態にします。
+func newCountingCharacterRepository(n int) *countingCharacterRepository {
+	repo := &countingCharacterRepository{}
+	acquiredAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		repo.userCharacters = append(repo.userCharacters, model.UserCharacter{
+			ID:          int64(i),
+			UserID:      1,
+			CharacterID: int64(i%5 + 1),
+			Level:       1,
+			AcquiredAt:  acquiredAt.Add(time.Duration(i) * time.Second),
+			Name:        fmt.Sprintf("Character %d", i%5+1),
+			Rarity:      i%5 + 1,
+		})
+	}
+	for i := 1; i <= 5; i++ {
+		repo.characters = append(repo.characters, model.Character{ID: int64(i), Name: fmt.Sprintf("Character %d", i), Rarity: i})
+	}
+	return repo
+}
+
+// listAllCharacters は所持キャラクター一覧を最大件数ずつ最後のページまで取得し、取得した件数とページ数を返します。
+func listAllCharacters(tb testing.TB, s CharacterService) (listed, pages int) {
+	tb.Helper()
+	cursor := ""
+	for {
+		list, err := s.ListCharacters(1, CharacterListQuery{Cursor: cursor, Limit: MaxCharacterListLimit})
+		if err != nil {
+			tb.Fatalf("ListCharacters: %v", err)
+		}
+		pages++
+		for _, c := range list.Characters {
+			if c.Name == "" {
+				tb.Fatalf("character %d has no name", c.UserCharacterID)
+			}
+		}
+		listed += len(list.Characters)
+		if list.NextCursor == "" {
+			return listed, pages
+		}
+		cursor = list.NextCursor
+	}
+}
+
+func TestListCharactersIssuesOneQueryPerPage(t *testing.T) {
+	for _, n := range []int{1, 1000} {
+		t.Run(fmt.Sprintf("%d characters", n), func(t *testing.T) {
+			repo := newCountingCharacterRepository(n)
+			s := NewCharacterService(repo, NewCharacterCache())
+
+			listed, pages := listAllCharacters(t, s)
+			if listed != n {
+				t.Errorf("listed %d characters, want %d", listed, n)
+			}
+			if repo.userCharacterQueries != pages {
+				t.Errorf("GetUserCharacters called %d times for %d pages, want one per page", repo.userCharacterQueries, pages)
+			}
+			if repo.characterQueries != 0 {
+				t.Errorf("GetCharacters called %d times, want 0 (names are joined by GetUserCharacters)", repo.characterQueries)
+			}
+		})
+	}
+}
+
+func BenchmarkListCharacters10000(b *testing.B) {
+	repo := newCountingCharacterRepository(10000)
+	s := NewCharacterService(repo, NewCharacterCache())
+
+	b.ResetTimer()
+	var pages int
+	for i := 0; i < b.N; i++ {
+		_, n := listAllCharacters(b, s)
+		pages += n
+	}
+	b.ReportMetric(float64(repo.userCharacterQueries+repo.characterQueries)/float64(b.N), "queries/op")
+	b.ReportMetric(float64(pages)/float64(b.N), "pages/op")
+}
+
+func TestCharacterMasterIsCached(t *testing.T) {
+	repo := newCountingCharacterRepository(0)
+	characters := NewCharacterCache()
+	s := NewCharacterService(repo, characters)
+
+	for i := 0; i < 3; i++ {
+		master, err := s.GetCharacterMaster()
+		if err != nil {
+			t.Fatalf("GetCharacterMaster: %v", err)
+		}
+		if len(master.Characters) != len(repo.characters) {
+			t.Fatalf("got %d characters, want %d", len(master.Characters), len(repo.characters))
+		}
+	}
+	if repo.characterQueries != 1 {
+		t.Errorf("GetCharacters called %d times, want 1", repo.characterQueries)
+	}
+
+	// キャッシュを破棄した後は1回だけ再読み込みする
+	characters.Invalidate()
+	if _, err := s.GetCharacterMaster(); err != nil {
+		t.Fatalf("GetCharacterMaster: %v", err)
+	}
+	if repo.characterQueries != 2 {
+		t.Errorf("GetCharacters called %d times after invalidation, want 2", repo.characterQueries)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// CharacterMaster はキャラクターのマスターデータを表す構造体です。
+// duplicateMode は所持済みのキャラクターを再度獲得したときの扱い(keep / item / limit_break)です。
+type CharacterMaster struct {
+	CharacterID           int64  `json:"characterID"`
+	Name                  string `json:"name"`
+	Rarity                int    `json:"rarity"`
+	DuplicateMode         string `json:"duplicateMode"`
+	DuplicateItemID       int64  `json:"duplicateItemID"`
+	DuplicateItemQuantity int    `json:"duplicateItemQuantity"`
+	MaxLimitBreak         int    `json:"maxLimitBreak"`
+}
+
+// CharacterMasterList はキャラクターのマスターデータの一覧と、その内容から算出した ETag を表す構造体です。
+// ETag はマスターデータが変わらない限り同じ値になるため、クライアントのキャッシュの検証に使用できます。
+type CharacterMasterList struct {
+	Characters []CharacterMaster
+	ETag       string
+}
+
+// GetCharacterMaster はすべてのキャラクターのマスターデータをキャラクターIDの昇順で取得します。
+// キャラクターのキャッシュから組み立てるため、データベースへのクエリはキャッシュの期限切れ時のみ発行されます。
+func (s *characterService) GetCharacterMaster() (*CharacterMasterList, error) {
+	characters, err := s.characters.get(s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &CharacterMasterList{Characters: make([]CharacterMaster, 0, len(characters))}
+	for _, c := range characters {
+		list.Characters = append(list.Characters, CharacterMaster{
+			CharacterID:           c.ID,
+			Name:                  c.Name,
+			Rarity:                c.Rarity,
+			DuplicateMode:         c.DuplicateMode,
+			DuplicateItemID:       c.DuplicateItemID,
+			DuplicateItemQuantity: c.DuplicateItemQuantity,
+			MaxLimitBreak:         c.MaxLimitBreak,
+		})
+	}
+	sort.Slice(list.Characters, func(i, j int) bool {
+		return list.Characters[i].CharacterID < list.Characters[j].CharacterID
+	})
+
+	body, err := json.Marshal(list.Characters)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	list.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	return list, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"sort"
+
+	"my-go-project/internal/repository"
+)
+
+// MaxSellCharacters は1回の売却で指定できる所持キャラクターの最大数です。
+const MaxSellCharacters = 100
+
+// SellResult は所持キャラクターの売却結果を表す構造体です。
+// Coin は売却で獲得したコイン、Balance は売却後のコイン残高です。
+type SellResult struct {
+	SoldUserCharacterIDs []int64    `json:"soldUserCharacterIDs"`
+	Coin                 int64      `json:"coin"`
+	Balance              int64      `json:"balance"`
+	Items                []SellItem `json:"items"`
+}
+
+// SellItem は売却で獲得したアイテムとその個数を表す構造体です。
+type SellItem struct {
+	ItemID   int64  `json:"itemID"`
+	Name     string `json:"name"`
+	Quantity int64  `json:"quantity"`
+}
+
+// SellCharacters は指定された所持キャラクターをまとめて売却し、レアリティごとの報酬のコインとアイテムを付与します。
+// 所持キャラクターの確認、削除、報酬の付与は1つのトランザクション内で行い、1体でも売却できない場合は何も売却しません。
+// ロックされたキャラクターが含まれる場合は ErrCharacterLocked を、チームに編成されたキャラクターが含まれる場合は ErrCharacterInTeam を返します。
+// チームから外してから売却する必要があるため、売却によってチームの編成が変わることはありません。
+// 報酬が設定されていないレアリティのキャラクターは報酬なしで売却されます。
+func (s *characterService) SellCharacters(userID int64, userCharacterIDs []int64) (*SellResult, error) {
+	if len(userCharacterIDs) == 0 || len(userCharacterIDs) > MaxSellCharacters {
+		return nil, ErrInvalidSell
+	}
+	seen := make(map[int64]bool, len(userCharacterIDs))
+	for _, id := range userCharacterIDs {
+		if id <= 0 || seen[id] {
+			return nil, ErrInvalidSell
+		}
+		seen[id] = true
+	}
+
+	var result *SellResult
+	err := s.repo.Transaction(func(repo repository.CharacterRepository) error {
+		owned, err := repo.GetUserCharactersByIDs(userID, userCharacterIDs)
+		if err != nil {
+			return err
+		}
+		for _, id := range userCharacterIDs {
+			uc, ok := owned[id]
+			if !ok {
+				return ErrCharacterNotOwned
+			}
+			if uc.Locked {
+				return ErrCharacterLocked
+			}
+		}
+		if err := checkTeamAssigned(repo, userCharacterIDs); err != nil {
+			return err
+		}
+
+		rewards, err := repo.GetSellRewards()
+		if err != nil {
+			return err
+		}
+		var coin int64
+		quantities := make(map[int64]int64)
+		for _, id := range userCharacterIDs {
+			for _, reward := range rewards {
+				if reward.Rarity != owned[id].Rarity {
+					continue
+				}
+				coin += reward.Coin
+				if reward.ItemID > 0 && reward.ItemQuantity > 0 {
+					quantities[reward.ItemID] += reward.ItemQuantity
+				}
+			}
+		}
+
+		balance, err := repo.GetUserCoinForUpdate(userID)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteUserCharacters(userID, userCharacterIDs); err != nil {
+			return err
+		}
+		if coin > 0 {
+			if err := repo.AddUserCoin(userID, coin); err != nil {
+				return err
+			}
+		}
+		if err := repo.AddUserItems(userID, quantities); err != nil {
+			return err
+		}
+
+		result = &SellResult{
+			SoldUserCharacterIDs: userCharacterIDs,
+			Coin:                 coin,
+			Balance:              balance + coin,
+			Items:                make([]SellItem, 0, len(quantities)),
+		}
+		if len(quantities) > 0 {
+			itemIDs := make([]int64, 0, len(quantities))
+			for itemID := range quantities {
+				itemIDs = append(itemIDs, itemID)
+			}
+			sort.Slice(itemIDs, func(i, j int) bool { return itemIDs[i] < itemIDs[j] })
+			// アイテム名を取得するため付与後の所持アイテムを参照する
+			items, err := repo.GetUserItemsByIDs(userID, itemIDs)
+			if err != nil {
+				return err
+			}
+			for _, itemID := range itemIDs {
+				result.Items = append(result.Items, SellItem{
+					ItemID:   itemID,
+					Name:     items[itemID].Name,
+					Quantity: quantities[itemID],
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
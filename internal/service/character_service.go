@@ -0,0 +1,27 @@
+package service
+
+import (
+	"my-go-project/internal/repository"
+)
+
+// CharacterService は所持キャラクター(一覧、育成、売却、ロック・お気に入り)と所持アイテム関連のビジネスロジックを定義するインターフェースです。
+type CharacterService interface {
+	ListCharacters(userID int64, query CharacterListQuery) (*CharacterList, error)
+	GetCharacterMaster() (*CharacterMasterList, error)
+	FeedCharacter(userID int64, req FeedRequest) (*FeedResult, error)
+	SellCharacters(userID int64, userCharacterIDs []int64) (*SellResult, error)
+	UpdateCharacterFlags(userID int64, req CharacterFlagsRequest) ([]UserCharacterResponse, error)
+	ListItems(userID int64) ([]UserItemResponse, error)
+}
+
+// characterService は CharacterService インターフェースを実装する構造体です。
+type characterService struct {
+	repo       repository.CharacterRepository
+	characters *CharacterCache
+}
+
+// NewCharacterService は新しい CharacterService を生成します。
+// characters はキャラクターのマスターデータのキャッシュで、ガチャや図鑑のサービスと共有します。
+func NewCharacterService(repo repository.CharacterRepository, characters *CharacterCache) CharacterService {
+	return &characterService{repo, characters}
+}
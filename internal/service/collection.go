@@ -0,0 +1,294 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+// CollectionService はキャラクター図鑑と達成報酬関連のビジネスロジックを定義するインターフェースです。
+type CollectionService interface {
+	GetCollection(userID int64) (*Collection, error)
+	ClaimCollectionRewards(userID int64) (*CollectionClaim, error)
+	BackfillCollections() (int64, error)
+}
+
+// collectionService は CollectionService インターフェースを実装する構造体です。
+type collectionService struct {
+	repo       repository.CollectionRepository
+	characters *CharacterCache
+}
+
+// NewCollectionService は新しい CollectionService を生成します。
+// characters はキャラクターのマスターデータのキャッシュで、ガチャや所持キャラクターのサービスと共有します。
+func NewCollectionService(repo repository.CollectionRepository, characters *CharacterCache) CollectionService {
+	return &collectionService{repo, characters}
+}
+
+// Collection はユーザーの図鑑を表す構造体です。
+// Progress はすべてのキャラクターの達成状況、RarityProgress はレアリティごとの達成状況です。
+type Collection struct {
+	Characters     []CollectionEntry        `json:"characters"`
+	Progress       CollectionProgress       `json:"progress"`
+	RarityProgress []CollectionProgress     `json:"rarityProgress"`
+	Rewards        []CollectionRewardStatus `json:"rewards"`
+}
+
+// CollectionEntry は図鑑のキャラクター1体分の登録状況を表す構造体です。
+// Owned は一度でも獲得したことがあるかどうかで、売却などで所持しなくなった後も true のままです。
+type CollectionEntry struct {
+	CharacterID     int64      `json:"characterID"`
+	Name            string     `json:"name"`
+	Rarity          int        `json:"rarity"`
+	Owned           bool       `json:"owned"`
+	FirstAcquiredAt *time.Time `json:"firstAcquiredAt,omitempty"`
+}
+
+// CollectionProgress は図鑑の達成状況を表す構造体です。Rarity が 0 の場合はすべてのキャラクターの達成状況です。
+type CollectionProgress struct {
+	Rarity  int     `json:"rarity"`
+	Owned   int     `json:"owned"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// CollectionRewardInfo は図鑑の達成報酬を表す構造体です。
+// Rarity が 0 の場合はすべてのキャラクター、それ以外はそのレアリティのキャラクターの達成率が Percent 以上になったときに付与されます。
+type CollectionRewardInfo struct {
+	RewardID     int64 `json:"rewardID"`
+	Rarity       int   `json:"rarity"`
+	Percent      int   `json:"percent"`
+	Coin         int64 `json:"coin"`
+	ItemID       int64 `json:"itemID,omitempty"`
+	ItemQuantity int64 `json:"itemQuantity,omitempty"`
+}
+
+// CollectionRewardStatus は図鑑の達成報酬と、付与済みかどうかを表す構造体です。
+// Claimable は達成率が条件を満たしていて、まだ受け取っていないかどうかです。
+type CollectionRewardStatus struct {
+	CollectionRewardInfo
+	Granted   bool `json:"granted"`
+	Claimable bool `json:"claimable"`
+}
+
+// CollectionClaim は図鑑の達成報酬の受け取り結果を表す構造体です。
+// Coin は受け取ったコインの合計、Balance は受け取り後のコイン残高です。
+type CollectionClaim struct {
+	Rewards []CollectionRewardInfo `json:"rewards"`
+	Coin    int64                  `json:"coin"`
+	Balance int64                  `json:"balance"`
+}
+
+// collectionCounts は図鑑の登録数と総数を、レアリティごとに集計したものです。キー 0 はすべてのキャラクターの合計です。
+type collectionCounts struct {
+	owned map[int]int
+	total map[int]int
+}
+
+// GetCollection は指定されたユーザーの図鑑を、キャラクターIDの昇順に取得します。
+func (s *collectionService) GetCollection(userID int64) (*Collection, error) {
+	characters, err := s.characters.get(s.repo)
+	if err != nil {
+		return nil, err
+	}
+	collections, err := s.repo.GetUserCollections(userID)
+	if err != nil {
+		return nil, err
+	}
+	rewards, err := s.repo.GetCollectionRewards()
+	if err != nil {
+		return nil, err
+	}
+	granted, err := s.repo.GetGrantedCollectionRewards(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	acquired := make(map[int64]time.Time, len(collections))
+	for _, c := range collections {
+		acquired[c.CharacterID] = c.FirstAcquiredAt
+	}
+
+	collection := &Collection{
+		Characters: make([]CollectionEntry, 0, len(characters)),
+		Rewards:    make([]CollectionRewardStatus, 0, len(rewards)),
+	}
+	for _, c := range characters {
+		entry := CollectionEntry{CharacterID: c.ID, Name: c.Name, Rarity: c.Rarity}
+		if at, ok := acquired[c.ID]; ok {
+			entry.Owned = true
+			entry.FirstAcquiredAt = &at
+		}
+		collection.Characters = append(collection.Characters, entry)
+	}
+	sort.Slice(collection.Characters, func(i, j int) bool {
+		return collection.Characters[i].CharacterID < collection.Characters[j].CharacterID
+	})
+
+	counts := countCollection(characters, acquired)
+	collection.Progress = counts.progress(0)
+	rarities := make([]int, 0, len(counts.total))
+	for rarity := range counts.total {
+		if rarity > 0 {
+			rarities = append(rarities, rarity)
+		}
+	}
+	sort.Ints(rarities)
+	for _, rarity := range rarities {
+		collection.RarityProgress = append(collection.RarityProgress, counts.progress(rarity))
+	}
+
+	for _, reward := range rewards {
+		collection.Rewards = append(collection.Rewards, CollectionRewardStatus{
+			CollectionRewardInfo: collectionRewardInfo(reward),
+			Granted:              granted[reward.ID],
+			Claimable:            !granted[reward.ID] && counts.reached(reward.Rarity, reward.Percent),
+		})
+	}
+
+	return collection, nil
+}
+
+// ClaimCollectionRewards は図鑑の達成率が条件を満たしていて、まだ受け取っていない達成報酬をすべて付与します。
+// 付与済みの報酬の確認と付与はユーザーの行をロックした1つのトランザクション内で行い、同じ報酬が二重に付与されることはありません。
+// 受け取れる報酬がない場合は、空の Rewards と現在のコイン残高を返します。
+func (s *collectionService) ClaimCollectionRewards(userID int64) (*CollectionClaim, error) {
+	characters, err := s.characters.get(s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var claim *CollectionClaim
+	err = s.repo.Transaction(func(repo repository.CollectionRepository) error {
+		// 同じユーザーの受け取りを直列化するため、付与済みの報酬を確認する前にユーザーの行をロックする
+		balance, err := repo.GetUserCoinForUpdate(userID)
+		if err != nil {
+			return err
+		}
+		granted, err := repo.GetGrantedCollectionRewards(userID)
+		if err != nil {
+			return err
+		}
+		rewards, err := repo.GetCollectionRewards()
+		if err != nil {
+			return err
+		}
+		collections, err := repo.GetUserCollections(userID)
+		if err != nil {
+			return err
+		}
+		acquired := make(map[int64]time.Time, len(collections))
+		for _, c := range collections {
+			acquired[c.CharacterID] = c.FirstAcquiredAt
+		}
+		counts := countCollection(characters, acquired)
+
+		claim = &CollectionClaim{Rewards: make([]CollectionRewardInfo, 0)}
+		var rewardIDs []int64
+		items := make(map[int64]int64)
+		for _, reward := range rewards {
+			if granted[reward.ID] || !counts.reached(reward.Rarity, reward.Percent) {
+				continue
+			}
+			rewardIDs = append(rewardIDs, reward.ID)
+			claim.Rewards = append(claim.Rewards, collectionRewardInfo(reward))
+			claim.Coin += reward.Coin
+			if reward.ItemID > 0 && reward.ItemQuantity > 0 {
+				items[reward.ItemID] += reward.ItemQuantity
+			}
+		}
+
+		claim.Balance = balance + claim.Coin
+		if len(rewardIDs) == 0 {
+			return nil
+		}
+
+		if err := repo.AddGrantedCollectionRewards(userID, rewardIDs); err != nil {
+			return err
+		}
+		if claim.Coin > 0 {
+			if err := repo.AddUserCoin(userID, claim.Coin); err != nil {
+				return err
+			}
+		}
+		if len(items) > 0 {
+			if err := repo.AddUserItems(userID, items); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claim, nil
+}
+
+// BackfillCollections は図鑑の導入前に獲得していた所持キャラクターを図鑑に登録し、登録した件数を返します。
+// 起動時に毎回呼び出しても、登録済みの図鑑は変わりません。
+func (s *collectionService) BackfillCollections() (int64, error) {
+	return s.repo.BackfillUserCollections()
+}
+
+// collectResults はガチャの結果のキャラクターを図鑑に登録します。
+// 達成報酬はここでは付与せず、ClaimCollectionRewards で受け取ります。
+func collectResults(repo repository.GachaRepository, userID int64, results []GachaResult) error {
+	var characterIDs []int64
+	seen := make(map[int64]bool)
+	for _, result := range results {
+		if !seen[result.CharacterID] {
+			seen[result.CharacterID] = true
+			characterIDs = append(characterIDs, result.CharacterID)
+		}
+	}
+
+	_, err := repo.AddUserCollections(userID, characterIDs)
+	return err
+}
+
+// countCollection はマスターデータのキャラクターについて、図鑑の登録数と総数をレアリティごとに集計します。
+// マスターデータに存在しないキャラクターの登録は数えません。
+func countCollection(characters map[int64]model.Character, acquired map[int64]time.Time) collectionCounts {
+	counts := collectionCounts{owned: make(map[int]int), total: make(map[int]int)}
+	for _, c := range characters {
+		counts.total[0]++
+		counts.total[c.Rarity]++
+		if _, ok := acquired[c.ID]; ok {
+			counts.owned[0]++
+			counts.owned[c.Rarity]++
+		}
+	}
+	return counts
+}
+
+// progress は指定されたレアリティ(0 の場合はすべて)の達成状況を返します。
+func (c collectionCounts) progress(rarity int) CollectionProgress {
+	progress := CollectionProgress{Rarity: rarity, Owned: c.owned[rarity], Total: c.total[rarity]}
+	if progress.Total > 0 {
+		progress.Percent = float64(progress.Owned) * 100 / float64(progress.Total)
+	}
+	return progress
+}
+
+// reached は指定されたレアリティ(0 の場合はすべて)の達成率が percent 以上かどうかを返します。
+// 浮動小数点の誤差で境界の判定がずれないよう、整数で比較します。
+func (c collectionCounts) reached(rarity, percent int) bool {
+	total := c.total[rarity]
+	return total > 0 && c.owned[rarity]*100 >= percent*total
+}
+
+// collectionRewardInfo は図鑑の達成報酬をレスポンス用に変換します。
+func collectionRewardInfo(reward model.CollectionReward) CollectionRewardInfo {
+	return CollectionRewardInfo{
+		RewardID:     reward.ID,
+		Rarity:       reward.Rarity,
+		Percent:      reward.Percent,
+		Coin:         reward.Coin,
+		ItemID:       reward.ItemID,
+		ItemQuantity: reward.ItemQuantity,
+	}
+}
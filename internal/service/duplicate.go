@@ -0,0 +1,117 @@
+package service
+
+import (
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+// 重複したキャラクターの変換先の種類です。
+const (
+	ConversionItem       = "item"
+	ConversionLimitBreak = "limitBreak"
+)
+
+// Conversion は重複したキャラクターの変換内容を表す構造体です。
+// type が item の場合は itemID のアイテムを quantity 個、limitBreak の場合は限界突破後のレベル limitBreak を表します。
+type Conversion struct {
+	Type       string `json:"type"`
+	ItemID     int64  `json:"itemID,omitempty"`
+	Quantity   int    `json:"quantity,omitempty"`
+	LimitBreak int    `json:"limitBreak,omitempty"`
+}
+
+// grantResults はガチャの結果をユーザーに付与し、results の UserCharacterID、IsNew、ConvertedTo を設定します。
+// 所持していないキャラクターは所持キャラクターとして追加し、重複はキャラクターの DuplicateMode に従って
+// 新しい行の追加、アイテムへの変換、既存の行の限界突破のいずれかを行います。
+// 同じリクエスト内で初めて獲得したキャラクターも、2体目以降は重複として扱います。
+func grantResults(repo repository.GachaRepository, userID int64, characters map[int64]model.Character, results []GachaResult) error {
+	var characterIDs []int64
+	seen := make(map[int64]bool)
+	for _, result := range results {
+		if !seen[result.CharacterID] {
+			seen[result.CharacterID] = true
+			characterIDs = append(characterIDs, result.CharacterID)
+		}
+	}
+
+	owned, err := repo.GetOwnedCharacters(userID, characterIDs)
+	if err != nil {
+		return err
+	}
+	levels := make(map[int64]int, len(owned))
+	for characterID, uc := range owned {
+		levels[characterID] = uc.LimitBreak
+	}
+
+	// 結果ごとに付与方法を決める
+	obtained := make(map[int64]bool)
+	var newIndexes []int
+	var newCharacterIDs []int64
+	limitBreaks := make(map[int64]int)
+	items := make(map[int64]int64)
+	for i := range results {
+		result := &results[i]
+		character := characters[result.CharacterID]
+
+		if _, ok := owned[result.CharacterID]; !ok && !obtained[result.CharacterID] {
+			obtained[result.CharacterID] = true
+			result.IsNew = true
+			newIndexes = append(newIndexes, i)
+			newCharacterIDs = append(newCharacterIDs, result.CharacterID)
+			continue
+		}
+
+		switch {
+		case character.DuplicateMode == model.DuplicateModeLimitBreak && levels[result.CharacterID] < character.MaxLimitBreak:
+			levels[result.CharacterID]++
+			limitBreaks[result.CharacterID]++
+			result.ConvertedTo = &Conversion{Type: ConversionLimitBreak, LimitBreak: levels[result.CharacterID]}
+		case (character.DuplicateMode == model.DuplicateModeItem || character.DuplicateMode == model.DuplicateModeLimitBreak) &&
+			character.DuplicateItemID > 0 && character.DuplicateItemQuantity > 0:
+			items[character.DuplicateItemID] += int64(character.DuplicateItemQuantity)
+			result.ConvertedTo = &Conversion{Type: ConversionItem, ItemID: character.DuplicateItemID, Quantity: character.DuplicateItemQuantity}
+		default:
+			newIndexes = append(newIndexes, i)
+			newCharacterIDs = append(newCharacterIDs, result.CharacterID)
+		}
+	}
+
+	// 所持キャラクターを追加
+	if len(newCharacterIDs) > 0 {
+		userCharacterIDs, err := repo.AddUserCharacters(userID, newCharacterIDs)
+		if err != nil {
+			return err
+		}
+		for n, id := range userCharacterIDs {
+			result := &results[newIndexes[n]]
+			result.UserCharacterID = id
+			if result.IsNew {
+				owned[result.CharacterID] = model.UserCharacter{ID: id, UserID: userID, CharacterID: result.CharacterID}
+			}
+		}
+	}
+
+	// 限界突破は最初に獲得した所持キャラクターに加算する
+	if len(limitBreaks) > 0 {
+		increments := make(map[int64]int, len(limitBreaks))
+		for characterID, n := range limitBreaks {
+			increments[owned[characterID].ID] += n
+		}
+		if err := repo.AddLimitBreaks(increments); err != nil {
+			return err
+		}
+		for i := range results {
+			if results[i].ConvertedTo != nil && results[i].ConvertedTo.Type == ConversionLimitBreak {
+				results[i].UserCharacterID = owned[results[i].CharacterID].ID
+			}
+		}
+	}
+
+	if len(items) > 0 {
+		if err := repo.AddUserItems(userID, items); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrGachaNotFound は指定されたガチャが存在しないことを表すエラーです。
+	ErrGachaNotFound = errors.New("gacha not found")
+	// ErrGachaNotOpen は指定されたガチャが開催期間外であることを表すエラーです。
+	ErrGachaNotOpen = errors.New("gacha is not open")
+	// ErrGachaUnavailable は指定されたガチャのマスターデータが不正なため抽選できないことを表すエラーです。
+	ErrGachaUnavailable = errors.New("gacha is temporarily unavailable")
+	// ErrInsufficientCoins はコイン残高が不足していることを表すエラーです。
+	ErrInsufficientCoins = errors.New("insufficient coins")
+	// ErrIdempotencyKeyReused は冪等キーが異なる内容のリクエストに再利用されたことを表すエラーです。
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with different parameters")
+	// ErrInvalidTimes は抽選回数が1未満であるか上限を超えている、またはコストの合計が表現できないことを表すエラーです。
+	ErrInvalidTimes = errors.New("invalid times")
+	// ErrStepUpCompleted はループしないステップアップガチャを最終ステップまで引き終えたことを表すエラーです。
+	ErrStepUpCompleted = errors.New("step-up gacha has been completed")
+	// ErrInvalidStepTimes は抽選回数がステップアップガチャの現在のステップと一致しないことを表すエラーです。
+	ErrInvalidStepTimes = errors.New("times does not match the current step")
+	// ErrNotBoxGacha は指定されたガチャが BOX ガチャではないことを表すエラーです。
+	ErrNotBoxGacha = errors.New("gacha is not a box gacha")
+	// ErrBoxInsufficient は BOX ガチャの残りが抽選回数に満たないことを表すエラーです。
+	ErrBoxInsufficient = errors.New("not enough items left in the box")
+	// ErrBoxNotResettable は BOX が空でなく目玉賞品も引いていないため、リセットできないことを表すエラーです。
+	ErrBoxNotResettable = errors.New("box cannot be reset until it is empty or the featured prize is drawn")
+	// ErrInvalidListQuery は一覧取得の並び替えキーや並び順が不正であることを表すエラーです。
+	ErrInvalidListQuery = errors.New("invalid sort or order")
+	// ErrInvalidCursor はページングのカーソルが不正であるか、並び替えの条件と一致しないことを表すエラーです。
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrCharacterNotOwned は指定された所持キャラクターが存在しないか、ユーザーの所持キャラクターでないことを表すエラーです。
+	ErrCharacterNotOwned = errors.New("character not found or not owned by the user")
+	// ErrInsufficientItems は消費するアイテムの所持数が不足していることを表すエラーです。
+	ErrInsufficientItems = errors.New("insufficient items")
+	// ErrInvalidFeed は育成で消費する素材やキャラクターの指定が不正であることを表すエラーです。
+	ErrInvalidFeed = errors.New("invalid feed materials")
+	// ErrMaxLevel は育成対象のキャラクターがすでにレベル上限に達していることを表すエラーです。
+	ErrMaxLevel = errors.New("character is already at max level")
+	// ErrInvalidSell は売却する所持キャラクターの指定が不正であることを表すエラーです。
+	ErrInvalidSell = errors.New("invalid characters to sell")
+	// ErrCharacterLocked は消費しようとした所持キャラクターがロックされていることを表すエラーです。
+	ErrCharacterLocked = errors.New("character is locked")
+	// ErrInvalidCharacterFlags はロック・お気に入りの更新内容が不正であることを表すエラーです。
+	ErrInvalidCharacterFlags = errors.New("invalid character flags update")
+	// ErrCharacterInTeam は消費しようとした所持キャラクターがチームに編成されていることを表すエラーです。
+	ErrCharacterInTeam = errors.New("character is assigned to a team")
+	// ErrTeamNotFound は指定されたチームが存在しないか、ユーザーのチームでないことを表すエラーです。
+	ErrTeamNotFound = errors.New("team not found")
+	// ErrInvalidTeam はチーム名や編成の内容が不正であることを表すエラーです。
+	ErrInvalidTeam = errors.New("invalid team")
+	// ErrTeamLimit はユーザーが作成できるチーム数の上限に達していることを表すエラーです。
+	ErrTeamLimit = errors.New("too many teams")
+	// ErrInvalidScore はゲームのスコアが負の値であるか、上限を超えていることを表すエラーです。
+	ErrInvalidScore = errors.New("invalid score")
+)
+
+// InsufficientCoinsError はコイン残高不足の詳細を表すエラーです。
+// errors.Is で ErrInsufficientCoins と比較できます。
+type InsufficientCoinsError struct {
+	Required int64
+	Balance  int64
+}
+
+func (e *InsufficientCoinsError) Error() string {
+	return fmt.Sprintf("%s: required %d, balance %d", ErrInsufficientCoins, e.Required, e.Balance)
+}
+
+func (e *InsufficientCoinsError) Is(target error) bool {
+	return target == ErrInsufficientCoins
+}
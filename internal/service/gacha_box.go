@@ -0,0 +1,169 @@
+package service
+
+import (
+	"fmt"
+
+	"my-go-project/internal/gacha"
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+// BoxStatus はユーザーの BOX ガチャの現在の BOX の中身を表す構造体です。
+// Resettable は BOX が空になったか目玉賞品を引いたため、リセットできる状態かどうかを表します。
+type BoxStatus struct {
+	GachaID       int64           `json:"gachaID"`
+	BoxNumber     int             `json:"boxNumber"`
+	Total         int             `json:"total"`
+	Remaining     int             `json:"remaining"`
+	FeaturedDrawn bool            `json:"featuredDrawn"`
+	Resettable    bool            `json:"resettable"`
+	Items         []BoxItemStatus `json:"items"`
+}
+
+// BoxItemStatus は BOX の中のキャラクター1種類分の残り数を表す構造体です。
+type BoxItemStatus struct {
+	CharacterID int64  `json:"characterID"`
+	Name        string `json:"name"`
+	Rarity      int    `json:"rarity"`
+	Featured    bool   `json:"featured"`
+	Quantity    int    `json:"quantity"`
+	Remaining   int    `json:"remaining"`
+}
+
+// GetBox は指定されたユーザーの、指定された BOX ガチャの現在の BOX の中身を取得します。
+// BOX ガチャでない場合は ErrNotBoxGacha を返します。
+func (s *gachaService) GetBox(userID, gachaID int64) (*BoxStatus, error) {
+	if _, err := s.gacha(gachaID); err != nil {
+		return nil, err
+	}
+	master, err := s.boxMaster(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	characters, err := s.characters.get(s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	box, contents, err := userBox(s.repo, master, userID, gachaID)
+	if err != nil {
+		return nil, err
+	}
+	return boxStatus(characters, box, contents), nil
+}
+
+// ResetBox は指定されたユーザーの BOX ガチャの BOX を初期状態に戻します。
+// BOX が空でなく、目玉賞品もまだ引いていない場合は ErrBoxNotResettable を返します。
+func (s *gachaService) ResetBox(userID, gachaID int64) (*BoxStatus, error) {
+	if _, err := s.openGacha(gachaID); err != nil {
+		return nil, err
+	}
+	master, err := s.boxMaster(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	characters, err := s.characters.get(s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var status *BoxStatus
+	err = s.repo.Transaction(func(repo repository.GachaRepository) error {
+		box, contents, err := userBox(repo, master, userID, gachaID)
+		if err != nil {
+			return err
+		}
+		if !boxResettable(box, contents) {
+			return ErrBoxNotResettable
+		}
+		if err := repo.ResetUserGachaBox(box); err != nil {
+			return err
+		}
+		status = boxStatus(characters, box, gacha.NewBox(master.Box, nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// boxMaster は指定された BOX ガチャのマスターデータを取得します。
+// BOX ガチャでない場合は ErrNotBoxGacha を返します。
+func (s *gachaService) boxMaster(gachaID int64) (*gachaMaster, error) {
+	master, err := s.validMaster(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	if !master.IsBox() {
+		return nil, ErrNotBoxGacha
+	}
+	return master, nil
+}
+
+// userBox はユーザーの現在の BOX の状態と中身を取得します。
+// トランザクション内で呼び出された場合は、BOX の状態と引いた数の行ロックを取得します。
+func userBox(repo repository.GachaRepository, master *gachaMaster, userID, gachaID int64) (*model.UserGachaBox, *gacha.Box, error) {
+	box, err := repo.GetUserGachaBox(userID, gachaID)
+	if err != nil {
+		return nil, nil, err
+	}
+	drawn, err := repo.GetUserGachaBoxDraws(userID, gachaID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return box, gacha.NewBox(master.Box, drawn), nil
+}
+
+// checkBoxRemaining は BOX に times 個以上残っているかを確認し、足りない場合は ErrBoxInsufficient を返します。
+func checkBoxRemaining(contents *gacha.Box, times int) error {
+	if remaining := contents.RemainingTotal(); times > remaining {
+		return fmt.Errorf("%w: %d left", ErrBoxInsufficient, remaining)
+	}
+	return nil
+}
+
+// saveBoxDraws は BOX から引いた結果を保存し、目玉賞品を引いた場合はリセット可能な状態にします。
+func saveBoxDraws(repo repository.GachaRepository, box *model.UserGachaBox, pulls []gacha.Pull) error {
+	drawn := make(map[int64]int)
+	for _, pull := range pulls {
+		drawn[pull.Item.CharacterID]++
+		if pull.Featured {
+			box.FeaturedDrawn = true
+		}
+	}
+	if err := repo.AddUserGachaBoxDraws(box.UserID, box.GachaID, drawn); err != nil {
+		return err
+	}
+	return repo.SaveUserGachaBox(box)
+}
+
+// boxResettable は BOX が空になったか、目玉賞品を引いたかどうかを返します。
+func boxResettable(box *model.UserGachaBox, contents *gacha.Box) bool {
+	return box.FeaturedDrawn || contents.RemainingTotal() == 0
+}
+
+// boxStatus は BOX の状態と中身からレスポンス用の BoxStatus を組み立てます。
+func boxStatus(characters map[int64]model.Character, box *model.UserGachaBox, contents *gacha.Box) *BoxStatus {
+	status := &BoxStatus{
+		GachaID:       box.GachaID,
+		BoxNumber:     box.BoxNumber,
+		Total:         contents.Total(),
+		Remaining:     contents.RemainingTotal(),
+		FeaturedDrawn: box.FeaturedDrawn,
+		Resettable:    boxResettable(box, contents),
+		Items:         make([]BoxItemStatus, 0, len(contents.Items)),
+	}
+	for i, item := range contents.Items {
+		status.Items = append(status.Items, BoxItemStatus{
+			CharacterID: item.CharacterID,
+			Name:        characterName(characters, item.CharacterID),
+			Rarity:      item.Rarity,
+			Featured:    item.Featured,
+			Quantity:    item.Quantity,
+			Remaining:   contents.Remaining[i],
+		})
+	}
+	return status
+}
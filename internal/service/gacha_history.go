@@ -0,0 +1,79 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// DefaultHistoryLimit は履歴取得時に件数が指定されなかった場合の取得件数です。
+	DefaultHistoryLimit = 20
+	// MaxHistoryLimit は履歴取得時に1回で取得できる最大件数です。
+	MaxHistoryLimit = 100
+)
+
+// DrawHistory はガチャ実行履歴の1ページを表す構造体です。
+// NextCursor は次のページを取得するためのカーソルで、次のページがない場合は 0 です。
+type DrawHistory struct {
+	History    []DrawHistoryEntry `json:"history"`
+	NextCursor int64              `json:"nextCursor"`
+}
+
+// DrawHistoryEntry はガチャ1回分(1リクエスト分)の実行履歴を表す構造体です。
+type DrawHistoryEntry struct {
+	DrawID    string        `json:"drawID"`
+	GachaID   int64         `json:"gachaID"`
+	Times     int           `json:"times"`
+	Results   []GachaResult `json:"results"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// GetHistory は指定されたユーザーのガチャ実行履歴を新しい順に取得します。
+// cursor には前のページの NextCursor を指定し、0 の場合は最新の履歴から取得します。
+func (s *gachaService) GetHistory(userID, cursor int64, limit int) (*DrawHistory, error) {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if limit > MaxHistoryLimit {
+		limit = MaxHistoryLimit
+	}
+
+	// 次のページの有無を判定するため1件多く取得する
+	logs, err := s.repo.GetDrawLogs(userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &DrawHistory{History: make([]DrawHistoryEntry, 0, limit)}
+	if len(logs) > limit {
+		logs = logs[:limit]
+		history.NextCursor = logs[limit-1].ID
+	}
+
+	for _, log := range logs {
+		var results []GachaResult
+		if err := json.Unmarshal(log.Results, &results); err != nil {
+			return nil, err
+		}
+		history.History = append(history.History, DrawHistoryEntry{
+			DrawID:    log.BatchID,
+			GachaID:   log.GachaID,
+			Times:     log.Times,
+			Results:   results,
+			CreatedAt: log.CreatedAt,
+		})
+	}
+
+	return history, nil
+}
+
+// newBatchID はガチャ実行ログのバッチIDとして使用するランダムな文字列を生成します。
+func newBatchID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
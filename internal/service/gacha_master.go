@@ -0,0 +1,201 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"my-go-project/internal/gacha"
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+// masterCacheTTL はガチャのマスターデータをキャッシュする期間です。
+// マスターデータの更新時は InvalidateMasterCache で即座に破棄できますが、
+// 複数のサーバーで運用する場合に備えて一定時間で再読み込みします。
+const masterCacheTTL = 5 * time.Minute
+
+// gachaMaster はガチャ1つ分の抽選に必要なマスターデータです。
+// problems が空でない場合、そのガチャのマスターデータは不正であり抽選に使用してはいけません。
+type gachaMaster struct {
+	*gacha.Banner
+	problems []gacha.Problem
+	loadedAt time.Time
+}
+
+// valid はマスターデータに問題がないかどうかを返します。
+func (m *gachaMaster) valid() bool {
+	return len(m.problems) == 0
+}
+
+// masterCache はガチャごとのマスターデータをメモリ上にキャッシュします。
+type masterCache struct {
+	mu      sync.RWMutex
+	masters map[int64]*gachaMaster
+	ttl     time.Duration
+}
+
+// newMasterCache は新しい masterCache を生成します。
+func newMasterCache(ttl time.Duration) *masterCache {
+	return &masterCache{
+		masters: make(map[int64]*gachaMaster),
+		ttl:     ttl,
+	}
+}
+
+// get は指定されたガチャのマスターデータを返します。
+// キャッシュにない場合や期限切れの場合は load で読み込んでキャッシュします。
+func (c *masterCache) get(gachaID int64, load func() (*gachaMaster, error)) (*gachaMaster, error) {
+	c.mu.RLock()
+	master, ok := c.masters[gachaID]
+	c.mu.RUnlock()
+	if ok && time.Since(master.loadedAt) < c.ttl {
+		return master, nil
+	}
+
+	master, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.masters[gachaID] = master
+	c.mu.Unlock()
+
+	return master, nil
+}
+
+// invalidate はキャッシュしているすべてのマスターデータを破棄します。
+func (c *masterCache) invalidate() {
+	c.mu.Lock()
+	c.masters = make(map[int64]*gachaMaster)
+	c.mu.Unlock()
+}
+
+// loadGachaMaster は指定されたガチャのマスターデータを読み込んで検証し、抽選テーブル(ステップアップガチャの場合はステップごとのテーブル、BOX ガチャの場合は BOX の中身を含む)を構築します。
+func loadGachaMaster(repo repository.GachaRepository, characters map[int64]model.Character, gachaID int64) (*gachaMaster, error) {
+	items, _, err := repo.GetGachaItems(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := repo.GetPitySettings(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	guarantees, err := repo.GetGachaGuarantees(gachaID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := repo.GetGachaSteps(gachaID)
+	if err != nil {
+		return nil, err
+	}
+
+	rateUps, err := repo.GetGachaRateUps(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	box, err := repo.GetGachaBoxItems(gachaID)
+	if err != nil {
+		return nil, err
+	}
+
+	banner := gacha.NewBanner(items, settings, guarantees, rateUps, steps)
+	banner.Box = box
+	problems := banner.Validate(characters)
+	if len(problems) > 0 {
+		log.Printf("gacha %d has invalid master data and will not be served: %+v", gachaID, problems)
+	}
+
+	return &gachaMaster{
+		Banner:   banner,
+		problems: problems,
+		loadedAt: time.Now(),
+	}, nil
+}
+
+// master は指定されたガチャのマスターデータをキャッシュから取得します。
+// マスターデータが不正な場合でも問題点を参照できるよう、エラーにはせずそのまま返します。
+func (s *gachaService) master(gachaID int64) (*gachaMaster, error) {
+	return s.masters.get(gachaID, func() (*gachaMaster, error) {
+		characters, err := s.characters.get(s.repo)
+		if err != nil {
+			return nil, err
+		}
+		return loadGachaMaster(s.repo, characters, gachaID)
+	})
+}
+
+// validMaster は指定されたガチャのマスターデータを取得します。
+// マスターデータが不正な場合は ErrGachaUnavailable を返します。
+func (s *gachaService) validMaster(gachaID int64) (*gachaMaster, error) {
+	master, err := s.master(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	if !master.valid() {
+		return nil, ErrGachaUnavailable
+	}
+	return master, nil
+}
+
+// InvalidateMasterCache はキャッシュしているガチャのマスターデータを破棄します。
+// 確率テーブルや天井設定などを更新した後に呼び出します。キャラクターのマスターデータは CharacterCache.Invalidate で破棄します。
+func (s *gachaService) InvalidateMasterCache() {
+	s.masters.invalidate()
+}
+
+// MasterValidation はガチャ1つ分のマスターデータの検証結果を表す構造体です。
+type MasterValidation struct {
+	GachaID  int64           `json:"gachaID"`
+	Name     string          `json:"name"`
+	Valid    bool            `json:"valid"`
+	Problems []gacha.Problem `json:"problems"`
+}
+
+// ValidateMaster はすべてのガチャのマスターデータをキャラクターを含めてデータベースから読み込んで検証し、その結果を返します。
+// キャッシュしているマスターデータは置き換えないため、抽選には影響しません。データベースを更新した後、抽選に反映する前の確認に使用します。
+func (s *gachaService) ValidateMaster() ([]MasterValidation, error) {
+	list, err := s.repo.GetCharacters()
+	if err != nil {
+		return nil, err
+	}
+	characters := charactersByID(list)
+
+	return s.validateMasters(func(gachaID int64) (*gachaMaster, error) {
+		return loadGachaMaster(s.repo, characters, gachaID)
+	})
+}
+
+// ReloadMaster はキャッシュしているガチャのマスターデータを破棄して読み込み直し、その検証結果を返します。
+// 起動時とマスターデータの更新後に呼び出され、不正なガチャは以降の抽選で ErrGachaUnavailable になります。
+// キャラクターのマスターデータは CharacterCache.Invalidate で先に破棄しておきます。
+func (s *gachaService) ReloadMaster() ([]MasterValidation, error) {
+	s.InvalidateMasterCache()
+	return s.validateMasters(s.master)
+}
+
+// validateMasters はすべてのガチャについて load でマスターデータを取得し、その検証結果を返します。
+func (s *gachaService) validateMasters(load func(gachaID int64) (*gachaMaster, error)) ([]MasterValidation, error) {
+	gachas, err := s.repo.GetGachas()
+	if err != nil {
+		return nil, err
+	}
+
+	validations := make([]MasterValidation, 0, len(gachas))
+	for _, g := range gachas {
+		master, err := load(g.ID)
+		if err != nil {
+			return nil, err
+		}
+		validations = append(validations, MasterValidation{
+			GachaID:  g.ID,
+			Name:     g.Name,
+			Valid:    master.valid(),
+			Problems: master.problems,
+		})
+	}
+
+	return validations, nil
+}
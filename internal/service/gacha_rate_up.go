@@ -0,0 +1,54 @@
+package service
+
+import (
+	"my-go-project/internal/model"
+)
+
+// RateUpInfo はレアリティ1つ分のピックアップ設定を表す構造体です。
+// share はそのレアリティを引いたときにピックアップキャラクターが選ばれる割合で、
+// guarantee はすり抜けた場合に次回そのレアリティを引いたときピックアップキャラクターが確定するかどうかを表します。
+type RateUpInfo struct {
+	Rarity       int     `json:"rarity"`
+	Share        float64 `json:"share"`
+	Guarantee    bool    `json:"guarantee"`
+	CharacterIDs []int64 `json:"characterIDs"`
+}
+
+// RateUpStatus はピックアップ設定と、ユーザーのピックアップ確定の状態を表す構造体です。
+// guaranteed は次にそのレアリティを引いたときにピックアップキャラクターが確定しているかどうかを表します。
+type RateUpStatus struct {
+	RateUpInfo
+	Guaranteed bool `json:"guaranteed"`
+}
+
+// rateUpInfos はピックアップ設定をレスポンス用の RateUpInfo に変換します。
+func rateUpInfos(master *gachaMaster) []RateUpInfo {
+	infos := make([]RateUpInfo, 0, len(master.RateUps))
+	for _, rateUp := range master.RateUps {
+		infos = append(infos, RateUpInfo{
+			Rarity:       rateUp.Rarity,
+			Share:        rateUp.Share,
+			Guarantee:    rateUp.Guarantee,
+			CharacterIDs: rateUp.CharacterIDs,
+		})
+	}
+	return infos
+}
+
+// rateUpStatuses はピックアップ設定とユーザーの状態からレスポンス用の RateUpStatus を組み立てます。
+func rateUpStatuses(master *gachaMaster, userRateUps []model.UserGachaRateUp) []RateUpStatus {
+	guaranteed := make(map[int]bool, len(userRateUps))
+	for _, rateUp := range userRateUps {
+		guaranteed[rateUp.Rarity] = rateUp.Guaranteed
+	}
+
+	infos := rateUpInfos(master)
+	statuses := make([]RateUpStatus, 0, len(infos))
+	for _, info := range infos {
+		statuses = append(statuses, RateUpStatus{
+			RateUpInfo: info,
+			Guaranteed: guaranteed[info.Rarity],
+		})
+	}
+	return statuses
+}
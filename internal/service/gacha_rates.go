@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"my-go-project/internal/gacha"
+	"my-go-project/internal/model"
+)
+
+// GachaRates はガチャ1つ分の提供割合を表す構造体です。
+// Rates と RarityRates は通常枠の排出率で、確定枠の排出率は Guarantees に含まれます。
+// ステップアップガチャの場合、ステップごとの排出率補正を適用した排出率は Steps に含まれます。
+// BOX ガチャの場合、Rates と RarityRates は初期の BOX から最初の1回を引くときの排出率です。
+// ピックアップ設定がある場合、Rates はピックアップによる調整後の排出率で、すり抜け後の確定は含みません。
+type GachaRates struct {
+	GachaID     int64             `json:"gachaID"`
+	Name        string            `json:"name"`
+	Rates       []CharacterRate   `json:"rates"`
+	RarityRates []RarityRate      `json:"rarityRates"`
+	Guarantees  []GuaranteeRates  `json:"guarantees"`
+	Pity        []PitySettingInfo `json:"pity"`
+	RateUps     []RateUpInfo      `json:"rateUps,omitempty"`
+	Steps       []StepRates       `json:"steps,omitempty"`
+}
+
+// CharacterRate はキャラクターごとの排出率を表す構造体です。
+type CharacterRate struct {
+	CharacterID int64   `json:"characterID"`
+	Name        string  `json:"name"`
+	Rarity      int     `json:"rarity"`
+	Rate        float64 `json:"rate"`
+	Featured    bool    `json:"featured"`
+}
+
+// RarityRate はレアリティごとの排出率を表す構造体です。
+type RarityRate struct {
+	Rarity int     `json:"rarity"`
+	Rate   float64 `json:"rate"`
+}
+
+// GuaranteeRates は確定枠の排出率を表す構造体です。
+type GuaranteeRates struct {
+	DrawCount   int             `json:"drawCount"`
+	MinRarity   int             `json:"minRarity"`
+	Rates       []CharacterRate `json:"rates"`
+	RarityRates []RarityRate    `json:"rarityRates"`
+}
+
+// StepRates はステップアップガチャの1ステップ分の排出率を表す構造体です。
+// Guarantee はステップの最後の1枠の確定枠の排出率で、確定枠がないステップでは省略されます。
+type StepRates struct {
+	Step        int             `json:"step"`
+	Times       int             `json:"times"`
+	Cost        int64           `json:"cost"`
+	Rates       []CharacterRate `json:"rates"`
+	RarityRates []RarityRate    `json:"rarityRates"`
+	Guarantee   *GuaranteeRates `json:"guarantee,omitempty"`
+}
+
+// PitySettingInfo は天井設定を表す構造体です。
+type PitySettingInfo struct {
+	Rarity        int     `json:"rarity"`
+	HardPity      int     `json:"hardPity"`
+	SoftPityStart int     `json:"softPityStart"`
+	SoftPityStep  float64 `json:"softPityStep"`
+}
+
+// GetRates はガチャの提供割合を取得します。
+// gachaID が 0 の場合は開催中のすべてのガチャの提供割合を返し、マスターデータが不正なガチャは含めません。
+// gachaID を指定した場合は、そのガチャのマスターデータが不正であれば ErrGachaUnavailable を返します。
+// 排出率は DrawGacha と同じ確率テーブルと正規化方法で算出されます。
+func (s *gachaService) GetRates(gachaID int64) ([]GachaRates, error) {
+	var gachas []model.Gacha
+	if gachaID == 0 {
+		open, err := s.repo.GetOpenGachas(time.Now())
+		if err != nil {
+			return nil, err
+		}
+		gachas = open
+	} else {
+		g, err := s.gacha(gachaID)
+		if err != nil {
+			return nil, err
+		}
+		gachas = []model.Gacha{*g}
+	}
+
+	rates := make([]GachaRates, 0, len(gachas))
+	for _, g := range gachas {
+		r, err := s.gachaRates(&g)
+		// 一覧ではマスターデータが不正なガチャを ListGachas と同様に含めない
+		if gachaID == 0 && errors.Is(err, ErrGachaUnavailable) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, *r)
+	}
+
+	return rates, nil
+}
+
+// gachaRates は指定されたガチャの提供割合を算出します。
+func (s *gachaService) gachaRates(g *model.Gacha) (*GachaRates, error) {
+	// DrawGacha と同じキャッシュ済みのマスターデータから算出する
+	master, err := s.validMaster(g.ID)
+	if err != nil {
+		return nil, err
+	}
+	items := master.Table.Items()
+	guarantees := master.Guarantees
+	settings := master.Pity
+
+	rates := &GachaRates{
+		GachaID:    g.ID,
+		Name:       g.Name,
+		Guarantees: make([]GuaranteeRates, 0, len(guarantees)),
+		Pity:       make([]PitySettingInfo, 0, len(settings)),
+	}
+
+	characters, err := s.characters.get(s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	base := gacha.Rates(items, 0)
+	if master.IsBox() {
+		base = gacha.BoxRates(master.Box)
+	}
+	rates.Rates = characterRates(characters, base)
+	rates.RarityRates = rarityRates(gacha.RarityRates(base))
+
+	for _, guarantee := range guarantees {
+		guaranteed := gacha.Rates(items, guarantee.MinRarity)
+		rates.Guarantees = append(rates.Guarantees, GuaranteeRates{
+			DrawCount:   guarantee.DrawCount,
+			MinRarity:   guarantee.MinRarity,
+			Rates:       characterRates(characters, guaranteed),
+			RarityRates: rarityRates(gacha.RarityRates(guaranteed)),
+		})
+	}
+
+	for _, step := range master.Steps {
+		stepItems := step.Table.Items()
+		stepBase := gacha.Rates(stepItems, 0)
+		stepRates := StepRates{
+			Step:        step.Step,
+			Times:       step.Times,
+			Cost:        step.Cost,
+			Rates:       characterRates(characters, stepBase),
+			RarityRates: rarityRates(gacha.RarityRates(stepBase)),
+		}
+		if guarantee, ok := step.Guarantee(); ok {
+			guaranteed := gacha.Rates(stepItems, guarantee.MinRarity)
+			stepRates.Guarantee = &GuaranteeRates{
+				DrawCount:   guarantee.DrawCount,
+				MinRarity:   guarantee.MinRarity,
+				Rates:       characterRates(characters, guaranteed),
+				RarityRates: rarityRates(gacha.RarityRates(guaranteed)),
+			}
+		}
+		rates.Steps = append(rates.Steps, stepRates)
+	}
+
+	if len(master.RateUps) > 0 {
+		rates.RateUps = rateUpInfos(master)
+		markFeatured(master, rates.Rates)
+		for _, guarantee := range rates.Guarantees {
+			markFeatured(master, guarantee.Rates)
+		}
+		for _, step := range rates.Steps {
+			markFeatured(master, step.Rates)
+			if step.Guarantee != nil {
+				markFeatured(master, step.Guarantee.Rates)
+			}
+		}
+	}
+
+	for _, setting := range settings {
+		rates.Pity = append(rates.Pity, PitySettingInfo{
+			Rarity:        setting.Rarity,
+			HardPity:      setting.HardPity,
+			SoftPityStart: setting.SoftPityStart,
+			SoftPityStep:  setting.SoftPityStep,
+		})
+	}
+
+	return rates, nil
+}
+
+// characterRates は gacha.Rate にキャラクター名を付与してレスポンス用に変換します。
+func characterRates(characters map[int64]model.Character, rates []gacha.Rate) []CharacterRate {
+	converted := make([]CharacterRate, 0, len(rates))
+	for _, rate := range rates {
+		converted = append(converted, CharacterRate{
+			CharacterID: rate.CharacterID,
+			Name:        characterName(characters, rate.CharacterID),
+			Rarity:      rate.Rarity,
+			Rate:        rate.Rate,
+		})
+	}
+	return converted
+}
+
+// markFeatured はピックアップキャラクターの CharacterRate に Featured を設定します。
+func markFeatured(master *gachaMaster, rates []CharacterRate) {
+	for i := range rates {
+		if rateUp, ok := master.RateUp(rates[i].Rarity); ok {
+			rates[i].Featured = rateUp.IsFeatured(rates[i].CharacterID)
+		}
+	}
+}
+
+// rarityRates は gacha.RarityRate をレスポンス用に変換します。
+func rarityRates(rates []gacha.RarityRate) []RarityRate {
+	converted := make([]RarityRate, 0, len(rates))
+	for _, rate := range rates {
+		converted = append(converted, RarityRate{Rarity: rate.Rarity, Rate: rate.Rate})
+	}
+	return converted
+}
@@ -1,104 +1,518 @@
 package service
 
 import (
-	"math/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
 	"time"
 
+	"my-go-project/internal/gacha"
+	"my-go-project/internal/model"
 	"my-go-project/internal/repository"
 )
 
 // GachaService はガチャ関連のビジネスロジックを定義するインターフェースです。
 type GachaService interface {
-	DrawGacha(userID int64, times int) ([]GachaResult, error)
-	ListCharacters(userID int64) ([]UserCharacterResponse, error)
+	DrawGacha(userID, gachaID int64, times int, idempotencyKey string) (*DrawResult, error)
+	ListGachas(userID int64) ([]GachaBanner, error)
+	GetPity(userID, gachaID int64) ([]PityStatus, error)
+	GetHistory(userID, cursor int64, limit int) (*DrawHistory, error)
+	GetRates(gachaID int64) ([]GachaRates, error)
+	GetBox(userID, gachaID int64) (*BoxStatus, error)
+	ResetBox(userID, gachaID int64) (*BoxStatus, error)
+	InvalidateMasterCache()
+	ValidateMaster() ([]MasterValidation, error)
+	ReloadMaster() ([]MasterValidation, error)
+}
+
+// DefaultGachaID は gachaID が指定されなかった場合に使用する常設ガチャのIDです。
+const DefaultGachaID int64 = 1
+
+// IdempotencyKeyTTL は冪等キーに対応するガチャ結果を再送する期間です。
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// MaxDrawTimes は1回のリクエストで引けるガチャの最大回数です。
+const MaxDrawTimes = 100
+
+// GachaBanner は開催中のガチャ(バナー)の情報を表す構造体です。
+type GachaBanner struct {
+	GachaID int64      `json:"gachaID"`
+	Name    string     `json:"name"`
+	Cost    int64      `json:"cost"`
+	StartAt *time.Time `json:"startAt,omitempty"`
+	EndAt   *time.Time `json:"endAt,omitempty"`
+	// Guarantees は複数回ガチャの確定枠ルールです。
+	Guarantees []GuaranteeRule `json:"guarantees"`
+	// RateUps はピックアップ設定とユーザーのピックアップ確定の状態です。ピックアップ設定がないガチャでは省略されます。
+	RateUps []RateUpStatus `json:"rateUps,omitempty"`
+	// StepUp はステップアップガチャのステップ構成とユーザーの進行状況です。通常のガチャでは省略されます。
+	StepUp *StepUpStatus `json:"stepUp,omitempty"`
+	// Box は BOX ガチャのユーザーの現在の BOX の中身です。通常のガチャでは省略されます。
+	Box *BoxStatus `json:"box,omitempty"`
+}
+
+// GuaranteeRule は複数回ガチャの確定枠ルールを表す構造体です。
+// drawCount 回ごとの最後の1枠は minRarity 以上のキャラクターから抽選されます。
+type GuaranteeRule struct {
+	DrawCount int `json:"drawCount"`
+	MinRarity int `json:"minRarity"`
+}
+
+// DrawResult はガチャの実行結果と、実行後の天井カウンターおよびコイン残高を表す構造体です。
+type DrawResult struct {
+	// DrawID はガチャ実行ログ(gacha_draw_logs)のバッチIDです。
+	DrawID  string        `json:"drawID"`
+	Results []GachaResult `json:"results"`
+	Pity    []PityStatus  `json:"pity"`
+	Coin    int64         `json:"coin"`
+	// RateUps は実行後のピックアップ確定の状態です。ピックアップ設定がないガチャでは省略されます。
+	RateUps []RateUpStatus `json:"rateUps,omitempty"`
+	// StepUp はステップアップガチャの実行後の進行状況です。通常のガチャでは省略されます。
+	StepUp *StepUpStatus `json:"stepUp,omitempty"`
+	// Box は BOX ガチャの実行後の BOX の中身です。通常のガチャでは省略されます。
+	Box *BoxStatus `json:"box,omitempty"`
+	// Replayed は冪等キーにより保存済みの結果を再送したかどうかを表します。
+	Replayed bool `json:"-"`
 }
 
 // GachaResult はガチャの結果を表す構造体です。
+// UserCharacterID は付与された所持キャラクターのIDで、限界突破に変換された場合は限界突破した所持キャラクター、
+// アイテムに変換された場合は 0 です。
 type GachaResult struct {
-	CharacterID int64  `json:"characterID"`
-	Name        string `json:"name"`
+	UserCharacterID int64  `json:"userCharacterID"`
+	CharacterID     int64  `json:"characterID"`
+	Name            string `json:"name"`
+	Rarity          int    `json:"rarity"`
+	// Guaranteed は確定枠で抽選された結果かどうかを表します。
+	Guaranteed bool `json:"guaranteed"`
+	// Featured はピックアップキャラクター、または BOX ガチャの目玉賞品かどうかを表します。
+	Featured bool `json:"featured"`
+	// RateUpGuaranteed は前回のすり抜けによる確定でピックアップキャラクターが選ばれたかどうかを表します。
+	RateUpGuaranteed bool `json:"rateUpGuaranteed"`
+	// IsNew はこの結果で初めて獲得したキャラクターかどうかを表します。
+	IsNew bool `json:"isNew"`
+	// ConvertedTo は重複したキャラクターが変換された場合の変換内容です。変換されなかった場合は省略されます。
+	ConvertedTo *Conversion `json:"convertedTo,omitempty"`
+}
+
+// PityStatus はレアリティごとの天井カウンターの状態を表す構造体です。
+// Remaining は天井まで残り何回かを表し、天井が設定されていない場合は 0 です。
+type PityStatus struct {
+	Rarity    int `json:"rarity"`
+	Count     int `json:"count"`
+	HardPity  int `json:"hardPity"`
+	Remaining int `json:"remaining"`
 }
 
 // UserCharacterResponse はユーザーが所持するキャラクター情報を表す構造体です。
+// LimitBreak は重複により限界突破した回数、Exp はこれまでに獲得した累計経験値です。
+// Locked のキャラクターは育成の素材や売却に使用できません。
 type UserCharacterResponse struct {
-	UserCharacterID int64  `json:"userCharacterID"`
-	CharacterID     int64  `json:"characterID"`
-	Name            string `json:"name"`
+	UserCharacterID int64     `json:"userCharacterID"`
+	CharacterID     int64     `json:"characterID"`
+	Name            string    `json:"name"`
+	Rarity          int       `json:"rarity"`
+	LimitBreak      int       `json:"limitBreak"`
+	Level           int       `json:"level"`
+	Exp             int64     `json:"exp"`
+	Locked          bool      `json:"locked"`
+	Favorite        bool      `json:"favorite"`
+	AcquiredAt      time.Time `json:"acquiredAt"`
 }
 
 // gachaService は GachaService インターフェースを実装する構造体です。
 type gachaService struct {
-	repo repository.GachaRepository
+	repo       repository.GachaRepository
+	rng        gacha.RNGSource
+	masters    *masterCache
+	characters *CharacterCache
 }
 
 // NewGachaService は新しい GachaService を生成します。
-func NewGachaService(repo repository.GachaRepository) GachaService {
-	return &gachaService{repo}
+// rng は抽選に使用する乱数生成器で、本番では gacha.NewCryptoRNGSource を、
+// テストや抽選の再現では gacha.NewSeededRNGSource を渡します。
+// characters はキャラクターのマスターデータのキャッシュで、所持キャラクターや図鑑のサービスと共有します。
+func NewGachaService(repo repository.GachaRepository, rng gacha.RNGSource, characters *CharacterCache) GachaService {
+	return &gachaService{repo, rng, newMasterCache(masterCacheTTL), characters}
 }
 
-// DrawGacha は指定された回数だけガチャを引き、その結果を返します。
-func (s *gachaService) DrawGacha(userID int64, times int) ([]GachaResult, error) {
-	// ガチャアイテムと総確率を取得
-	items, totalProbability, err := s.repo.GetGachaItems()
+// DrawGacha は指定されたガチャを指定された回数だけ引き、その結果を返します。
+// idempotencyKey が指定された場合、同じキーの最初の結果を IdempotencyKeyTTL の間保存して再送し、
+// 同じキーの同時リクエストは直列化されます。異なる内容のリクエストに同じキーが使われた場合は ErrIdempotencyKeyReused を返します。
+func (s *gachaService) DrawGacha(userID, gachaID int64, times int, idempotencyKey string) (*DrawResult, error) {
+	if times <= 0 || times > MaxDrawTimes {
+		return nil, ErrInvalidTimes
+	}
+	if idempotencyKey == "" {
+		return s.draw(s.repo, userID, gachaID, times)
+	}
+
+	var draw *DrawResult
+	err := s.repo.Transaction(func(repo repository.GachaRepository) error {
+		// 同じキーのリクエストが実行中であれば、ここでそのトランザクションの終了を待つ
+		req, err := repo.ReserveDrawRequest(userID, idempotencyKey, gachaID, times)
+		if err != nil {
+			return err
+		}
+
+		// 有効期間の切れた結果のみ、異なる内容のリクエストでキーを再利用できる
+		expired := req.Response != nil && time.Since(req.CreatedAt) >= IdempotencyKeyTTL
+		if !expired && (req.GachaID != gachaID || req.Times != times) {
+			return ErrIdempotencyKeyReused
+		}
+
+		// 有効期間内の結果が保存されていれば再送する
+		if req.Response != nil && !expired {
+			draw = &DrawResult{}
+			if err := json.Unmarshal(req.Response, draw); err != nil {
+				return err
+			}
+			draw.Replayed = true
+			return nil
+		}
+
+		draw, err = s.draw(repo, userID, gachaID, times)
+		if err != nil {
+			return err
+		}
+
+		response, err := json.Marshal(draw)
+		if err != nil {
+			return err
+		}
+		return repo.SaveDrawResponse(userID, idempotencyKey, gachaID, times, response)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// シードされた乱数ジェネレーターを使用
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	var results []GachaResult
-	var characterIDs []int64
-	for i := 0; i < times; i++ {
-		r := rnd.Float64() * totalProbability
-		var cumulative float64
-		for _, item := range items {
-			cumulative += item.Probability
-			if r <= cumulative {
-				name, err := s.repo.GetCharacterName(item.CharacterID)
-				if err != nil {
-					name = "Unknown"
-				}
-				results = append(results, GachaResult{
-					CharacterID: item.CharacterID,
-					Name:        name,
-				})
-				characterIDs = append(characterIDs, item.CharacterID)
-				break
+	return draw, nil
+}
+
+// draw はガチャを引く処理の本体です。
+// 確定枠ルールに該当する枠は、対象レアリティ以上のキャラクターのみから抽選します。
+// コインの消費、天井カウンターの更新、キャラクターの付与は同じトランザクション内で行われ、
+// 残高が不足している場合は InsufficientCoinsError を返します。
+// ステップアップガチャの場合は、ユーザーの現在のステップのコスト・排出率・確定枠で抽選し、ステップを1つ進めます。
+// BOX ガチャの場合は、ユーザーの現在の BOX から非復元抽出で抽選し、残りが足りなければ ErrBoxInsufficient を返します。
+// repo がトランザクション内のリポジトリであれば、そのトランザクションに参加します。
+func (s *gachaService) draw(repo repository.GachaRepository, userID, gachaID int64, times int) (*DrawResult, error) {
+	// 開催中のガチャかどうかを確認
+	g, err := s.openGacha(gachaID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 抽選テーブル、天井設定、確定枠ルールを取得 (検証に失敗したガチャは引けない)
+	master, err := s.validMaster(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	settings := master.Pity
+	characters, err := s.characters.get(s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// 乱数生成器を生成 (シードは監査ログに記録する)
+	rnd, seed, err := s.rng.New()
+	if err != nil {
+		return nil, err
+	}
+	batchID, err := newBatchID()
+	if err != nil {
+		return nil, err
+	}
+
+	var draw DrawResult
+	err = repo.Transaction(func(repo repository.GachaRepository) error {
+		// ステップアップガチャの場合は進行状況をロックして、現在のステップのコストを使用する
+		// コストの乗算がオーバーフローする場合は引かせない
+		if g.Cost > math.MaxInt64/int64(times) {
+			return ErrInvalidTimes
+		}
+		cost := g.Cost * int64(times)
+		var progress *model.UserGachaStep
+		var step gacha.Step
+		if master.IsStepUp() {
+			progress, err = repo.GetUserGachaStep(userID, gachaID)
+			if err != nil {
+				return err
+			}
+			step, err = currentStep(master, progress, times)
+			if err != nil {
+				return err
 			}
+			cost = step.Cost
 		}
-	}
 
-	// ユーザーにキャラクターを追加
-	if len(characterIDs) > 0 {
-		if err := s.repo.AddUserCharacters(userID, characterIDs); err != nil {
-			return nil, err
+		// BOX ガチャの場合は BOX の状態をロックして、残りが足りるかを確認する
+		var box *model.UserGachaBox
+		var contents *gacha.Box
+		if master.IsBox() {
+			box, contents, err = userBox(repo, master, userID, gachaID)
+			if err != nil {
+				return err
+			}
+			if err := checkBoxRemaining(contents, times); err != nil {
+				return err
+			}
+		}
+
+		// コイン残高をロックして確認し、消費する
+		coin, err := repo.GetUserCoinForUpdate(userID)
+		if err != nil {
+			return err
+		}
+		if coin < cost {
+			return &InsufficientCoinsError{Required: cost, Balance: coin}
+		}
+		if cost > 0 {
+			if err := repo.AddUserCoin(userID, -cost); err != nil {
+				return err
+			}
+		}
+		draw.Coin = coin - cost
+
+		// 天井カウンターをロックして取得
+		pities, err := repo.GetUserPities(userID, gachaID)
+		if err != nil {
+			return err
+		}
+		state := gacha.NewPityState(pities)
+
+		// ピックアップ確定の状態をロックして取得
+		userRateUps, err := repo.GetUserRateUps(userID, gachaID)
+		if err != nil {
+			return err
+		}
+		rateUpState := gacha.NewRateUpState(userRateUps)
+
+		var pulls []gacha.Pull
+		var ok bool
+		switch {
+		case contents != nil:
+			pulls, ok = contents.Draw(times, rnd)
+		case progress != nil:
+			pulls, ok = master.DrawStep(state, rateUpState, step, rnd)
+		default:
+			pulls, ok = master.DrawBatch(state, rateUpState, times, rnd)
 		}
+		if !ok {
+			return ErrGachaUnavailable
+		}
+
+		for _, pull := range pulls {
+			draw.Results = append(draw.Results, GachaResult{
+				CharacterID:      pull.Item.CharacterID,
+				Name:             characterName(characters, pull.Item.CharacterID),
+				Rarity:           pull.Item.Rarity,
+				Guaranteed:       pull.Guaranteed,
+				Featured:         pull.Featured,
+				RateUpGuaranteed: pull.RateUpGuaranteed,
+			})
+		}
+
+		// ユーザーにキャラクターを付与 (重複はキャラクターごとのルールで変換する)
+		if err := grantResults(repo, userID, characters, draw.Results); err != nil {
+			return err
+		}
+
+		// 図鑑に登録 (達成報酬は ClaimCollectionRewards で受け取る)
+		if err := collectResults(repo, userID, draw.Results); err != nil {
+			return err
+		}
+
+		// 監査ログを記録
+		results, err := json.Marshal(draw.Results)
+		if err != nil {
+			return err
+		}
+		if _, err := repo.CreateDrawLog(&model.GachaDrawLog{
+			BatchID: batchID,
+			UserID:  userID,
+			GachaID: gachaID,
+			Times:   times,
+			Seed:    seed,
+			Results: results,
+		}); err != nil {
+			return err
+		}
+		draw.DrawID = batchID
+
+		// 天井カウンターを保存
+		userPities := state.UserPities(userID, settings)
+		if err := repo.SaveUserPities(userID, gachaID, userPities); err != nil {
+			return err
+		}
+		draw.Pity = pityStatuses(settings, userPities)
+
+		// ピックアップ確定の状態を保存
+		if len(master.RateUps) > 0 {
+			userRateUps = rateUpState.UserRateUps(userID, master.RateUps)
+			if err := repo.SaveUserRateUps(userID, gachaID, userRateUps); err != nil {
+				return err
+			}
+			draw.RateUps = rateUpStatuses(master, userRateUps)
+		}
+
+		// ステップアップガチャの進行状況を保存
+		if progress != nil {
+			advanceStep(g, master, progress)
+			if err := repo.SaveUserGachaStep(progress); err != nil {
+				return err
+			}
+			draw.StepUp = stepUpStatus(g, master, progress)
+		}
+
+		// BOX ガチャの引いた数を保存
+		if contents != nil {
+			if err := saveBoxDraws(repo, box, pulls); err != nil {
+				return err
+			}
+			draw.Box = boxStatus(characters, box, contents)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return results, nil
+	return &draw, nil
 }
 
-// ListCharacters は指定されたユーザーが所持するキャラクターの一覧を取得します。
-func (s *gachaService) ListCharacters(userID int64) ([]UserCharacterResponse, error) {
-	// ユーザーが所持するキャラクターを取得
-	userCharacters, err := s.repo.GetUserCharacters(userID)
+// ListGachas は現在開催中のガチャ(バナー)の一覧を取得します。
+// マスターデータの検証に失敗しているガチャは含まれません。
+// ステップアップガチャには指定されたユーザーの現在のステップを、BOX ガチャには現在の BOX の中身を含めます。
+func (s *gachaService) ListGachas(userID int64) ([]GachaBanner, error) {
+	gachas, err := s.repo.GetOpenGachas(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	characters, err := s.characters.get(s.repo)
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []UserCharacterResponse
-	for _, uc := range userCharacters {
-		name, err := s.repo.GetCharacterName(uc.CharacterID)
+	banners := make([]GachaBanner, 0, len(gachas))
+	for _, g := range gachas {
+		master, err := s.master(g.ID)
 		if err != nil {
-			name = "Unknown"
+			return nil, err
+		}
+		// マスターデータが不正なガチャは一覧に含めない
+		if !master.valid() {
+			continue
+		}
+
+		rules := make([]GuaranteeRule, 0, len(master.Guarantees))
+		for _, guarantee := range master.Guarantees {
+			rules = append(rules, GuaranteeRule{
+				DrawCount: guarantee.DrawCount,
+				MinRarity: guarantee.MinRarity,
+			})
+		}
+
+		banner := GachaBanner{
+			GachaID:    g.ID,
+			Name:       g.Name,
+			Cost:       g.Cost,
+			StartAt:    g.StartAt,
+			EndAt:      g.EndAt,
+			Guarantees: rules,
+		}
+		if len(master.RateUps) > 0 {
+			userRateUps, err := s.repo.GetUserRateUps(userID, g.ID)
+			if err != nil {
+				return nil, err
+			}
+			banner.RateUps = rateUpStatuses(master, userRateUps)
 		}
-		responses = append(responses, UserCharacterResponse{
-			UserCharacterID: uc.ID,
-			CharacterID:     uc.CharacterID,
-			Name:            name,
-		})
+		if master.IsStepUp() {
+			progress, err := s.repo.GetUserGachaStep(userID, g.ID)
+			if err != nil {
+				return nil, err
+			}
+			banner.StepUp = stepUpStatus(&g, master, progress)
+		}
+		if master.IsBox() {
+			box, contents, err := userBox(s.repo, master, userID, g.ID)
+			if err != nil {
+				return nil, err
+			}
+			banner.Box = boxStatus(characters, box, contents)
+		}
+
+		banners = append(banners, banner)
+	}
+
+	return banners, nil
+}
+
+// GetPity は指定されたユーザーの、指定されたガチャにおける現在の天井カウンターを取得します。
+func (s *gachaService) GetPity(userID, gachaID int64) ([]PityStatus, error) {
+	if _, err := s.gacha(gachaID); err != nil {
+		return nil, err
+	}
+
+	master, err := s.master(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	pities, err := s.repo.GetUserPities(userID, gachaID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := gacha.NewPityState(pities)
+	return pityStatuses(master.Pity, state.UserPities(userID, master.Pity)), nil
+}
+
+// gacha は指定されたIDのガチャを取得します。存在しない場合は ErrGachaNotFound を返します。
+func (s *gachaService) gacha(gachaID int64) (*model.Gacha, error) {
+	g, err := s.repo.GetGacha(gachaID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGachaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// openGacha は指定されたIDの開催中のガチャを取得します。開催期間外の場合は ErrGachaNotOpen を返します。
+func (s *gachaService) openGacha(gachaID int64) (*model.Gacha, error) {
+	g, err := s.gacha(gachaID)
+	if err != nil {
+		return nil, err
+	}
+	if !g.IsOpen(time.Now()) {
+		return nil, ErrGachaNotOpen
 	}
+	return g, nil
+}
 
-	return responses, nil
+// pityStatuses は天井設定とカウンターからレスポンス用の PityStatus を組み立てます。
+func pityStatuses(settings []model.PitySetting, pities []model.UserPity) []PityStatus {
+	counts := make(map[int]int, len(pities))
+	for _, pity := range pities {
+		counts[pity.Rarity] = pity.Count
+	}
+
+	statuses := make([]PityStatus, 0, len(settings))
+	for _, setting := range settings {
+		status := PityStatus{
+			Rarity:   setting.Rarity,
+			Count:    counts[setting.Rarity],
+			HardPity: setting.HardPity,
+		}
+		if setting.HardPity > 0 {
+			status.Remaining = setting.HardPity - status.Count
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
 }
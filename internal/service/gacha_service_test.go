@@ -0,0 +1,398 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"my-go-project/internal/gacha"
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+// memoryGachaRepository は通常のガチャ1つ分のマスターデータとユーザーの状態をメモリ上に保持する GachaRepository です。
+// ステップアップ、BOX、ピックアップの設定はなく、それらの状態を参照するメソッドは空の結果を返します。
+// calls にはメソッドごとの呼び出し回数(実装ではそれぞれがクエリに相当します)を記録します。
+type memoryGachaRepository struct {
+	gacha      model.Gacha
+	items      []model.GachaProbability
+	characters []model.Character
+	coins      map[int64]int64
+	owned      map[int64]model.UserCharacter
+	userItems  map[int64]int64
+	requests   map[string]*model.GachaDrawRequest
+	nextID     int64
+	calls      map[string]int
+}
+
+// newMemoryGachaRepository は10体のキャラクターを同じ確率で排出する、コスト 10 のガチャ(ID 1)を返します。
+// 重複したキャラクターは、キャラクターごとにそのまま追加、アイテムへの変換、限界突破のいずれかになります。
+func newMemoryGachaRepository() *memoryGachaRepository {
+	repo := &memoryGachaRepository{
+		gacha:     model.Gacha{ID: 1, Name: "Test Gacha", Cost: 10},
+		coins:     make(map[int64]int64),
+		owned:     make(map[int64]model.UserCharacter),
+		userItems: make(map[int64]int64),
+		requests:  make(map[string]*model.GachaDrawRequest),
+		calls:     make(map[string]int),
+	}
+	modes := []string{model.DuplicateModeKeep, model.DuplicateModeItem, model.DuplicateModeLimitBreak}
+	for i := 1; i <= 10; i++ {
+		character := model.Character{
+			ID:            int64(i),
+			Name:          fmt.Sprintf("Character %d", i),
+			Rarity:        (i + 1) / 2,
+			DuplicateMode: modes[i%len(modes)],
+			MaxLimitBreak: 5,
+		}
+		if character.DuplicateMode != model.DuplicateModeKeep {
+			character.DuplicateItemID = 1
+			character.DuplicateItemQuantity = character.Rarity
+		}
+		repo.characters = append(repo.characters, character)
+		repo.items = append(repo.items, model.GachaProbability{
+			GachaID:     1,
+			CharacterID: character.ID,
+			Probability: 0.1,
+			Rarity:      character.Rarity,
+		})
+	}
+	return repo
+}
+
+// characterQueryMethods はキャラクターのマスターデータと所持キャラクターを読み書きするメソッドです。
+var characterQueryMethods = []string{"GetCharacters", "GetOwnedCharacters", "AddUserCharacters", "AddLimitBreaks", "AddUserCollections"}
+
+// characterQueries は characterQueryMethods の呼び出し回数の合計を返します。
+func (r *memoryGachaRepository) characterQueries() int {
+	n := 0
+	for _, method := range characterQueryMethods {
+		n += r.calls[method]
+	}
+	return n
+}
+
+// totalQueries はすべてのメソッドの呼び出し回数の合計を返します。Transaction は数えません。
+func (r *memoryGachaRepository) totalQueries() int {
+	n := 0
+	for _, calls := range r.calls {
+		n += calls
+	}
+	return n
+}
+
+func (r *memoryGachaRepository) GetGacha(gachaID int64) (*model.Gacha, error) {
+	r.calls["GetGacha"]++
+	g := r.gacha
+	return &g, nil
+}
+
+func (r *memoryGachaRepository) GetGachas() ([]model.Gacha, error) {
+	r.calls["GetGachas"]++
+	return []model.Gacha{r.gacha}, nil
+}
+
+func (r *memoryGachaRepository) GetOpenGachas(now time.Time) ([]model.Gacha, error) {
+	r.calls["GetOpenGachas"]++
+	return []model.Gacha{r.gacha}, nil
+}
+
+func (r *memoryGachaRepository) GetGachaItems(gachaID int64) ([]model.GachaProbability, float64, error) {
+	r.calls["GetGachaItems"]++
+	var total float64
+	for _, item := range r.items {
+		total += item.Probability
+	}
+	return r.items, total, nil
+}
+
+func (r *memoryGachaRepository) GetGachaGuarantees(gachaID int64) ([]model.GachaGuarantee, error) {
+	r.calls["GetGachaGuarantees"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) GetUserCoinForUpdate(userID int64) (int64, error) {
+	r.calls["GetUserCoinForUpdate"]++
+	return r.coins[userID], nil
+}
+
+func (r *memoryGachaRepository) AddUserCoin(userID, delta int64) error {
+	r.calls["AddUserCoin"]++
+	r.coins[userID] += delta
+	return nil
+}
+
+func (r *memoryGachaRepository) ReserveDrawRequest(userID int64, key string, gachaID int64, times int) (*model.GachaDrawRequest, error) {
+	r.calls["ReserveDrawRequest"]++
+	id := fmt.Sprintf("%d/%s", userID, key)
+	req, ok := r.requests[id]
+	if !ok {
+		req = &model.GachaDrawRequest{UserID: userID, IdempotencyKey: key, GachaID: gachaID, Times: times, CreatedAt: time.Now()}
+		r.requests[id] = req
+	}
+	copied := *req
+	return &copied, nil
+}
+
+func (r *memoryGachaRepository) SaveDrawResponse(userID int64, key string, gachaID int64, times int, response []byte) error {
+	r.calls["SaveDrawResponse"]++
+	req := r.requests[fmt.Sprintf("%d/%s", userID, key)]
+	req.GachaID, req.Times, req.Response, req.CreatedAt = gachaID, times, response, time.Now()
+	return nil
+}
+
+func (r *memoryGachaRepository) CreateDrawLog(log *model.GachaDrawLog) (int64, error) {
+	r.calls["CreateDrawLog"]++
+	return 1, nil
+}
+
+func (r *memoryGachaRepository) GetDrawLogs(userID, beforeID int64, limit int) ([]model.GachaDrawLog, error) {
+	r.calls["GetDrawLogs"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) AddUserCharacters(userID int64, characterIDs []int64) ([]int64, error) {
+	r.calls["AddUserCharacters"]++
+	ids := make([]int64, 0, len(characterIDs))
+	for _, characterID := range characterIDs {
+		r.nextID++
+		if _, ok := r.owned[characterID]; !ok {
+			r.owned[characterID] = model.UserCharacter{ID: r.nextID, UserID: userID, CharacterID: characterID}
+		}
+		ids = append(ids, r.nextID)
+	}
+	return ids, nil
+}
+
+func (r *memoryGachaRepository) GetOwnedCharacters(userID int64, characterIDs []int64) (map[int64]model.UserCharacter, error) {
+	r.calls["GetOwnedCharacters"]++
+	owned := make(map[int64]model.UserCharacter)
+	for _, characterID := range characterIDs {
+		if uc, ok := r.owned[characterID]; ok {
+			owned[characterID] = uc
+		}
+	}
+	return owned, nil
+}
+
+func (r *memoryGachaRepository) AddLimitBreaks(increments map[int64]int) error {
+	r.calls["AddLimitBreaks"]++
+	for characterID, uc := range r.owned {
+		uc.LimitBreak += increments[uc.ID]
+		r.owned[characterID] = uc
+	}
+	return nil
+}
+
+func (r *memoryGachaRepository) AddUserItems(userID int64, quantities map[int64]int64) error {
+	r.calls["AddUserItems"]++
+	for itemID, quantity := range quantities {
+		r.userItems[itemID] += quantity
+	}
+	return nil
+}
+
+func (r *memoryGachaRepository) GetCharacters() ([]model.Character, error) {
+	r.calls["GetCharacters"]++
+	return r.characters, nil
+}
+
+func (r *memoryGachaRepository) AddUserCollections(userID int64, characterIDs []int64) ([]int64, error) {
+	r.calls["AddUserCollections"]++
+	return characterIDs, nil
+}
+
+func (r *memoryGachaRepository) GetPitySettings(gachaID int64) ([]model.PitySetting, error) {
+	r.calls["GetPitySettings"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) GetUserPities(userID, gachaID int64) ([]model.UserPity, error) {
+	r.calls["GetUserPities"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) SaveUserPities(userID, gachaID int64, pities []model.UserPity) error {
+	r.calls["SaveUserPities"]++
+	return nil
+}
+
+func (r *memoryGachaRepository) GetGachaSteps(gachaID int64) ([]model.GachaStep, error) {
+	r.calls["GetGachaSteps"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) GetUserGachaStep(userID, gachaID int64) (*model.UserGachaStep, error) {
+	r.calls["GetUserGachaStep"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) SaveUserGachaStep(progress *model.UserGachaStep) error {
+	r.calls["SaveUserGachaStep"]++
+	return nil
+}
+
+func (r *memoryGachaRepository) GetGachaBoxItems(gachaID int64) ([]model.GachaBoxItem, error) {
+	r.calls["GetGachaBoxItems"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) GetUserGachaBox(userID, gachaID int64) (*model.UserGachaBox, error) {
+	r.calls["GetUserGachaBox"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) SaveUserGachaBox(box *model.UserGachaBox) error {
+	r.calls["SaveUserGachaBox"]++
+	return nil
+}
+
+func (r *memoryGachaRepository) GetUserGachaBoxDraws(userID, gachaID int64) (map[int64]int, error) {
+	r.calls["GetUserGachaBoxDraws"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) AddUserGachaBoxDraws(userID, gachaID int64, drawn map[int64]int) error {
+	r.calls["AddUserGachaBoxDraws"]++
+	return nil
+}
+
+func (r *memoryGachaRepository) ResetUserGachaBox(box *model.UserGachaBox) error {
+	r.calls["ResetUserGachaBox"]++
+	return nil
+}
+
+func (r *memoryGachaRepository) GetGachaRateUps(gachaID int64) ([]model.GachaRateUp, error) {
+	r.calls["GetGachaRateUps"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) GetUserRateUps(userID, gachaID int64) ([]model.UserGachaRateUp, error) {
+	r.calls["GetUserRateUps"]++
+	return nil, nil
+}
+
+func (r *memoryGachaRepository) SaveUserRateUps(userID, gachaID int64, rateUps []model.UserGachaRateUp) error {
+	r.calls["SaveUserRateUps"]++
+	return nil
+}
+
+func (r *memoryGachaRepository) Transaction(fn func(repo repository.GachaRepository) error) error {
+	return fn(r)
+}
+
+// maxDrawCharacterQueries は1回の DrawGacha でキャラクターを読み書きするクエリの上限です。
+// マスターデータの読み込み、所持状況の取得、所持キャラクターの追加、限界突破、図鑑への登録の各1回で、引く回数には依存しません。
+const maxDrawCharacterQueries = 5
+
+func TestDrawGachaCharacterQueriesDoNotGrowWithTimes(t *testing.T) {
+	for _, times := range []int{1, 10, MaxDrawTimes} {
+		t.Run(fmt.Sprintf("%d pulls", times), func(t *testing.T) {
+			repo := newMemoryGachaRepository()
+			repo.coins[1] = repo.gacha.Cost * int64(times) * 2
+			s := NewGachaService(repo, gacha.NewSeededRNGSource(1), NewCharacterCache())
+
+			// 2回目は所持済みのキャラクターの重複の変換も含む
+			for i := 0; i < 2; i++ {
+				before := repo.characterQueries()
+				draw, err := s.DrawGacha(1, repo.gacha.ID, times, "")
+				if err != nil {
+					t.Fatalf("DrawGacha: %v", err)
+				}
+				if len(draw.Results) != times {
+					t.Fatalf("got %d results, want %d", len(draw.Results), times)
+				}
+				if n := repo.characterQueries() - before; n > maxDrawCharacterQueries {
+					t.Errorf("draw %d issued %d character queries, want at most %d (%v)", i+1, n, maxDrawCharacterQueries, repo.calls)
+				}
+			}
+			if repo.calls["GetCharacters"] != 1 {
+				t.Errorf("GetCharacters called %d times, want 1 (cached)", repo.calls["GetCharacters"])
+			}
+		})
+	}
+}
+
+func BenchmarkDrawGacha100Pulls(b *testing.B) {
+	repo := newMemoryGachaRepository()
+	s := NewGachaService(repo, gacha.NewSeededRNGSource(1), NewCharacterCache())
+	repo.coins[1] = repo.gacha.Cost * MaxDrawTimes * int64(b.N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.DrawGacha(1, repo.gacha.ID, MaxDrawTimes, ""); err != nil {
+			b.Fatalf("DrawGacha: %v", err)
+		}
+	}
+	b.ReportMetric(float64(repo.totalQueries())/float64(b.N), "queries/op")
+	b.ReportMetric(float64(repo.characterQueries())/float64(b.N), "character-queries/op")
+}
+
+func TestDrawGachaIdempotencyKey(t *testing.T) {
+	repo := newMemoryGachaRepository()
+	repo.coins[1] = 1000
+	s := NewGachaService(repo, gacha.NewSeededRNGSource(1), NewCharacterCache())
+
+	first, err := s.DrawGacha(1, repo.gacha.ID, 10, "key")
+	if err != nil {
+		t.Fatalf("DrawGacha: %v", err)
+	}
+	replayed, err := s.DrawGacha(1, repo.gacha.ID, 10, "key")
+	if err != nil {
+		t.Fatalf("DrawGacha (replay): %v", err)
+	}
+	if !replayed.Replayed || replayed.DrawID != first.DrawID {
+		t.Errorf("replay = %+v, want the first result replayed", replayed)
+	}
+	if _, err := s.DrawGacha(1, repo.gacha.ID, 1, "key"); err != ErrIdempotencyKeyReused {
+		t.Errorf("DrawGacha with different times error = %v, want ErrIdempotencyKeyReused", err)
+	}
+	if repo.coins[1] != 1000-repo.gacha.Cost*10 {
+		t.Errorf("coin = %d, want only the first draw charged", repo.coins[1])
+	}
+}
+
+func TestDrawGachaRejectsReusedKeyBeforeDrawing(t *testing.T) {
+	repo := newMemoryGachaRepository()
+	repo.coins[1] = 1000
+	// 別の内容のリクエストで確保済みで、まだ結果が保存されていないキー
+	repo.requests["1/key"] = &model.GachaDrawRequest{UserID: 1, IdempotencyKey: "key", GachaID: repo.gacha.ID, Times: 10, CreatedAt: time.Now()}
+	s := NewGachaService(repo, gacha.NewSeededRNGSource(1), NewCharacterCache())
+
+	if _, err := s.DrawGacha(1, repo.gacha.ID, 1, "key"); err != ErrIdempotencyKeyReused {
+		t.Fatalf("DrawGacha error = %v, want ErrIdempotencyKeyReused", err)
+	}
+	if repo.coins[1] != 1000 || repo.calls["CreateDrawLog"] != 0 {
+		t.Errorf("coin = %d, draw logs = %d, want no draw", repo.coins[1], repo.calls["CreateDrawLog"])
+	}
+}
+
+func TestValidateMasterDoesNotReplaceCachedMaster(t *testing.T) {
+	repo := newMemoryGachaRepository()
+	repo.coins[1] = 1000
+	s := NewGachaService(repo, gacha.NewSeededRNGSource(1), NewCharacterCache())
+	if _, err := s.DrawGacha(1, repo.gacha.ID, 1, ""); err != nil {
+		t.Fatalf("DrawGacha: %v", err)
+	}
+
+	// 確率の合計が 1 にならないよう更新する
+	repo.items = repo.items[1:]
+
+	validations, err := s.ValidateMaster()
+	if err != nil {
+		t.Fatalf("ValidateMaster: %v", err)
+	}
+	if len(validations) != 1 || validations[0].Valid {
+		t.Fatalf("ValidateMaster = %+v, want the updated master to be invalid", validations)
+	}
+	if _, err := s.DrawGacha(1, repo.gacha.ID, 1, ""); err != nil {
+		t.Errorf("DrawGacha after ValidateMaster: %v, want the cached master to be used", err)
+	}
+
+	if _, err := s.ReloadMaster(); err != nil {
+		t.Fatalf("ReloadMaster: %v", err)
+	}
+	if _, err := s.DrawGacha(1, repo.gacha.ID, 1, ""); err != ErrGachaUnavailable {
+		t.Errorf("DrawGacha after ReloadMaster error = %v, want ErrGachaUnavailable", err)
+	}
+}
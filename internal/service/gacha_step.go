@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+
+	"my-go-project/internal/gacha"
+	"my-go-project/internal/model"
+)
+
+// StepUpStatus はステップアップガチャのステップ構成とユーザーの進行状況を表す構造体です。
+// CurrentStep は次に引くステップで、Completed はループしないガチャの最終ステップを引き終えたことを表します。
+type StepUpStatus struct {
+	CurrentStep int        `json:"currentStep"`
+	TotalSteps  int        `json:"totalSteps"`
+	Loop        bool       `json:"loop"`
+	Completed   bool       `json:"completed"`
+	Steps       []StepInfo `json:"steps"`
+}
+
+// StepInfo はステップアップガチャの1ステップ分の設定を表す構造体です。
+// boostMultiplier は boostRarity 以上のキャラクターの重みに掛ける倍率で、1 の場合は排出率が変わりません。
+type StepInfo struct {
+	Step            int     `json:"step"`
+	Times           int     `json:"times"`
+	Cost            int64   `json:"cost"`
+	MinRarity       int     `json:"minRarity"`
+	BoostRarity     int     `json:"boostRarity"`
+	BoostMultiplier float64 `json:"boostMultiplier"`
+}
+
+// currentStep はユーザーの進行状況から次に引くステップの設定を取得します。
+// ループしないガチャを最後まで引き終えている場合は ErrStepUpCompleted を、
+// times がステップの抽選回数と一致しない場合は ErrInvalidStepTimes を返します。
+func currentStep(master *gachaMaster, progress *model.UserGachaStep, times int) (gacha.Step, error) {
+	if progress.Completed {
+		return gacha.Step{}, ErrStepUpCompleted
+	}
+	step, ok := master.Step(progress.CurrentStep)
+	if !ok {
+		// ステップ数が減らされた場合は最初のステップからやり直す
+		step = master.Steps[0]
+		progress.CurrentStep = step.Step
+	}
+	if times != step.Times {
+		return gacha.Step{}, fmt.Errorf("%w: step %d requires %d draws", ErrInvalidStepTimes, step.Step, step.Times)
+	}
+	return step, nil
+}
+
+// advanceStep はステップを1つ進めます。
+// 最終ステップを引き終えた場合、ループするガチャはステップ1に戻り、ループしないガチャは完了状態になります。
+func advanceStep(g *model.Gacha, master *gachaMaster, progress *model.UserGachaStep) {
+	if progress.CurrentStep < len(master.Steps) {
+		progress.CurrentStep++
+		return
+	}
+	if g.StepLoop {
+		progress.CurrentStep = 1
+		return
+	}
+	progress.Completed = true
+}
+
+// stepUpStatus はステップ構成と進行状況からレスポンス用の StepUpStatus を組み立てます。
+func stepUpStatus(g *model.Gacha, master *gachaMaster, progress *model.UserGachaStep) *StepUpStatus {
+	status := &StepUpStatus{
+		CurrentStep: progress.CurrentStep,
+		TotalSteps:  len(master.Steps),
+		Loop:        g.StepLoop,
+		Completed:   progress.Completed,
+		Steps:       make([]StepInfo, 0, len(master.Steps)),
+	}
+	if _, ok := master.Step(status.CurrentStep); !ok {
+		status.CurrentStep = 1
+	}
+	for _, step := range master.Steps {
+		status.Steps = append(status.Steps, StepInfo{
+			Step:            step.Step,
+			Times:           step.Times,
+			Cost:            step.Cost,
+			MinRarity:       step.MinRarity,
+			BoostRarity:     step.BoostRarity,
+			BoostMultiplier: step.BoostMultiplier,
+		})
+	}
+	return status
+}
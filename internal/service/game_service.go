@@ -0,0 +1,240 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+const (
+	// MaxGameScore は1回のゲームで送信できる最大スコアです。
+	MaxGameScore = 1000000
+	// ScorePerCoin は1コインの獲得に必要なスコアです。獲得コインはスコアを ScorePerCoin で割った値(切り捨て)です。
+	ScorePerCoin = 100
+	// DefaultRankingLimit はランキングの取得時に件数が指定されなかった場合の取得件数です。
+	DefaultRankingLimit = 10
+	// MaxRankingLimit はランキングを1回で取得できる最大件数です。
+	MaxRankingLimit = 100
+)
+
+// GameService はゲームのスコアとランキング関連のビジネスロジックを定義するインターフェースです。
+type GameService interface {
+	FinishGame(userID, score int64) (*GameFinishResult, error)
+	GetRanking(start int, cursor string, limit int) (*Ranking, error)
+}
+
+// GameFinishResult はゲーム終了時の結果を表す構造体です。
+// Coin は獲得したコイン、Balance は獲得後のコイン残高です。
+type GameFinishResult struct {
+	Score        int64 `json:"score"`
+	Coin         int64 `json:"coin"`
+	Balance      int64 `json:"balance"`
+	HighScore    int64 `json:"highScore"`
+	NewHighScore bool  `json:"newHighScore"`
+}
+
+// Ranking はランキングの1ページを表す構造体です。
+// NextCursor は次のページを取得するためのカーソルで、次のページがない場合は空文字列です。
+type Ranking struct {
+	Ranks      []RankEntry `json:"ranks"`
+	NextCursor string      `json:"nextCursor"`
+}
+
+// RankEntry はランキングの1行を表す構造体です。
+// 同じスコアのユーザーは同じ順位になり、次の順位はその人数分だけ飛びます(1, 2, 2, 4)。
+type RankEntry struct {
+	UserID   int64  `json:"userID"`
+	UserName string `json:"userName"`
+	Rank     int    `json:"rank"`
+	Score    int64  `json:"score"`
+}
+
+// gameService は GameService インターフェースを実装する構造体です。
+type gameService struct {
+	repo repository.GameRepository
+}
+
+// NewGameService は新しい GameService を生成します。
+func NewGameService(repo repository.GameRepository) GameService {
+	return &gameService{repo}
+}
+
+// FinishGame はゲームのスコアを記録し、スコアに応じたコインを付与します。
+// スコアの記録、コインの付与、最高スコアの更新は1つのトランザクション内で行います。
+func (s *gameService) FinishGame(userID, score int64) (*GameFinishResult, error) {
+	if score < 0 || score > MaxGameScore {
+		return nil, ErrInvalidScore
+	}
+	coin := score / ScorePerCoin
+
+	var result *GameFinishResult
+	err := s.repo.Transaction(func(repo repository.GameRepository) error {
+		balance, err := repo.GetUserCoinForUpdate(userID)
+		if err != nil {
+			return err
+		}
+		previous, err := repo.GetHighScore(userID)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.CreateGameScore(&model.GameScore{UserID: userID, Score: score, Coin: coin}); err != nil {
+			return err
+		}
+		if coin > 0 {
+			if err := repo.AddUserCoin(userID, coin); err != nil {
+				return err
+			}
+		}
+		if err := repo.SaveHighScore(userID, score); err != nil {
+			return err
+		}
+
+		result = &GameFinishResult{
+			Score:        score,
+			Coin:         coin,
+			Balance:      balance + coin,
+			HighScore:    score,
+			NewHighScore: previous == nil || score > previous.Score,
+		}
+		if !result.NewHighScore {
+			result.HighScore = previous.Score
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetRanking は最高スコアのランキングを limit 件取得します。
+// cursor には前のページの NextCursor を指定し、空の場合は start 番目(1始まり)の位置から取得します。
+// start は順位ではなくランキング上の位置で、同じ順位のユーザーがページをまたいでも漏れなく取得できます。
+// 2ページ目以降は並び順のキー(スコア、達成日時、ユーザーID)によるカーソル方式で、ページの位置に関係なく同じ計算量で取得できます。
+// start を指定した最初のページのみ、インデックスを start 件読み飛ばして開始位置の行を求め、上位の人数から順位を算出します。
+// 順位は先頭からの件数で決まるため、カーソルに前のページの最後の行の順位と位置を含めて引き継ぎます。
+// ページの取得の間に最高スコアが更新された場合、以降のページの順位は取得時点の順位とずれることがあります。
+func (s *gameService) GetRanking(start int, cursor string, limit int) (*Ranking, error) {
+	if start <= 0 {
+		start = 1
+	}
+	if limit <= 0 {
+		limit = DefaultRankingLimit
+	}
+	if limit > MaxRankingLimit {
+		limit = MaxRankingLimit
+	}
+
+	ranking := &Ranking{Ranks: make([]RankEntry, 0, limit)}
+	// 開始位置の算出とページの取得が同じ時点のデータに基づくよう、1つのトランザクション内で読み取る
+	err := s.repo.Transaction(func(repo repository.GameRepository) error {
+		var after *model.UserHighScore
+		var last rankingCursor
+		switch {
+		case cursor != "":
+			c, err := decodeRankingCursor(cursor)
+			if err != nil {
+				return err
+			}
+			last = *c
+			after = &model.UserHighScore{UserID: c.UserID, Score: c.Score, AchievedAt: c.AchievedAt}
+		case start > 1:
+			// 開始位置の直前の行を求め、その行の順位を上位の人数から算出する
+			prev, err := repo.GetRankingEntry(start - 1)
+			if err != nil || prev == nil {
+				return err
+			}
+			higher, err := repo.CountHigherScores(prev.Score)
+			if err != nil {
+				return err
+			}
+			last = rankingCursor{UserID: prev.UserID, Score: prev.Score, AchievedAt: prev.AchievedAt, Rank: higher + 1, Position: start - 1}
+			after = prev
+		}
+
+		// 次のページの有無を判定するため1件多く取得する
+		scores, err := repo.GetRanking(after, limit+1)
+		if err != nil {
+			return err
+		}
+		hasNext := len(scores) > limit
+		if hasNext {
+			scores = scores[:limit]
+		}
+
+		rank, position, previous := last.Rank, last.Position, last.Score
+		for _, hs := range scores {
+			position++
+			// 同じスコアは直前の行(前のページの最後の行を含む)と同じ順位にする
+			if position == 1 || hs.Score != previous {
+				rank = position
+			}
+			previous = hs.Score
+			ranking.Ranks = append(ranking.Ranks, RankEntry{
+				UserID:   hs.UserID,
+				UserName: hs.Name,
+				Rank:     rank,
+				Score:    hs.Score,
+			})
+		}
+
+		if hasNext {
+			next, err := encodeRankingCursor(scores[len(scores)-1], rank, position)
+			if err != nil {
+				return err
+			}
+			ranking.NextCursor = next
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ranking, nil
+}
+
+// rankingCursor はカーソルに埋め込む、ページの最後の行の並び順のキーと、その行の順位と位置(1始まり)です。
+type rankingCursor struct {
+	UserID     int64     `json:"u"`
+	Score      int64     `json:"s"`
+	AchievedAt time.Time `json:"a"`
+	Rank       int       `json:"r"`
+	Position   int       `json:"p"`
+}
+
+// encodeRankingCursor は最高スコア hs の次の行から取得するためのカーソルを生成します。
+func encodeRankingCursor(hs model.UserHighScore, rank, position int) (string, error) {
+	b, err := json.Marshal(rankingCursor{
+		UserID:     hs.UserID,
+		Score:      hs.Score,
+		AchievedAt: hs.AchievedAt,
+		Rank:       rank,
+		Position:   position,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeRankingCursor はカーソルを解析し、前のページの最後の行を返します。カーソルが不正な場合は ErrInvalidCursor を返します。
+func decodeRankingCursor(value string) (*rankingCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor rankingCursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.UserID <= 0 || cursor.Rank <= 0 || cursor.Position < cursor.Rank {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
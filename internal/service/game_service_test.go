@@ -0,0 +1,217 @@
+package service
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+// memoryGameRepository は最高スコアをメモリ上に保持する GameRepository です。
+// ランキングの取得は、実装と同じ並び順(スコアの降順、達成日時、ユーザーIDの昇順)で行います。
+type memoryGameRepository struct {
+	scores []model.UserHighScore
+	coins  map[int64]int64
+}
+
+func newMemoryGameRepository(scores ...model.UserHighScore) *memoryGameRepository {
+	repo := &memoryGameRepository{coins: make(map[int64]int64)}
+	for _, hs := range scores {
+		repo.scores = append(repo.scores, hs)
+	}
+	repo.sort()
+	return repo
+}
+
+func (r *memoryGameRepository) sort() {
+	sort.Slice(r.scores, func(i, j int) bool { return rankedBefore(r.scores[i], r.scores[j]) })
+}
+
+// rankedBefore は a がランキングの並び順で b より前かどうかを返します。
+func rankedBefore(a, b model.UserHighScore) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if !a.AchievedAt.Equal(b.AchievedAt) {
+		return a.AchievedAt.Before(b.AchievedAt)
+	}
+	return a.UserID < b.UserID
+}
+
+func (r *memoryGameRepository) CreateGameScore(score *model.GameScore) error {
+	return nil
+}
+
+func (r *memoryGameRepository) SaveHighScore(userID, score int64) error {
+	for i := range r.scores {
+		if r.scores[i].UserID == userID {
+			if score > r.scores[i].Score {
+				r.scores[i].Score = score
+				r.scores[i].AchievedAt = time.Now()
+			}
+			r.sort()
+			return nil
+		}
+	}
+	r.scores = append(r.scores, model.UserHighScore{UserID: userID, Score: score, AchievedAt: time.Now()})
+	r.sort()
+	return nil
+}
+
+func (r *memoryGameRepository) GetHighScore(userID int64) (*model.UserHighScore, error) {
+	for _, hs := range r.scores {
+		if hs.UserID == userID {
+			return &hs, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryGameRepository) GetRanking(after *model.UserHighScore, limit int) ([]model.UserHighScore, error) {
+	var page []model.UserHighScore
+	for _, hs := range r.scores {
+		if after != nil && !rankedBefore(*after, hs) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, hs)
+	}
+	return page, nil
+}
+
+func (r *memoryGameRepository) GetRankingEntry(position int) (*model.UserHighScore, error) {
+	if position <= 0 || position > len(r.scores) {
+		return nil, nil
+	}
+	hs := r.scores[position-1]
+	return &hs, nil
+}
+
+func (r *memoryGameRepository) CountHigherScores(score int64) (int, error) {
+	n := 0
+	for _, hs := range r.scores {
+		if hs.Score > score {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memoryGameRepository) GetUserCoinForUpdate(userID int64) (int64, error) {
+	return r.coins[userID], nil
+}
+
+func (r *memoryGameRepository) AddUserCoin(userID, delta int64) error {
+	r.coins[userID] += delta
+	return nil
+}
+
+func (r *memoryGameRepository) Transaction(fn func(repo repository.GameRepository) error) error {
+	return fn(r)
+}
+
+// rankingTestScores は同じスコアのユーザーを含むランキングです。
+// 並び順は 4, 2, 1, 3, 5 で、順位は 1, 2, 2, 2, 5 です (UserID 1 と 3 は達成日時が同じため、ユーザーIDの昇順)。
+func rankingTestScores() []model.UserHighScore {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []model.UserHighScore{
+		{UserID: 1, Score: 300, AchievedAt: at.Add(time.Second)},
+		{UserID: 2, Score: 300, AchievedAt: at},
+		{UserID: 3, Score: 300, AchievedAt: at.Add(time.Second)},
+		{UserID: 4, Score: 500, AchievedAt: at},
+		{UserID: 5, Score: 100, AchievedAt: at},
+	}
+}
+
+// collectRanking は start から limit 件ずつ、カーソルで最後のページまで取得します。
+func collectRanking(t *testing.T, s GameService, start, limit int) []RankEntry {
+	t.Helper()
+	var ranks []RankEntry
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("ranking did not end")
+		}
+		page, next := start, cursor
+		if cursor != "" {
+			page = 0
+		}
+		ranking, err := s.GetRanking(page, next, limit)
+		if err != nil {
+			t.Fatalf("GetRanking: %v", err)
+		}
+		ranks = append(ranks, ranking.Ranks...)
+		if ranking.NextCursor == "" {
+			return ranks
+		}
+		cursor = ranking.NextCursor
+	}
+}
+
+func TestGetRanking(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     int
+		limit     int
+		wantUsers []int64
+		wantRanks []int
+	}{
+		{
+			// ページの境界が同じスコアの途中になる
+			name:      "from the top",
+			start:     1,
+			limit:     2,
+			wantUsers: []int64{4, 2, 1, 3, 5},
+			wantRanks: []int{1, 2, 2, 2, 5},
+		},
+		{
+			// 開始位置が同じスコアの途中でも、上位の人数から順位を求める
+			name:      "from a tied position",
+			start:     3,
+			limit:     2,
+			wantUsers: []int64{1, 3, 5},
+			wantRanks: []int{2, 2, 5},
+		},
+		{
+			name:      "from the last position",
+			start:     5,
+			limit:     10,
+			wantUsers: []int64{5},
+			wantRanks: []int{5},
+		},
+		{
+			name:  "past the end",
+			start: 6,
+			limit: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewGameService(newMemoryGameRepository(rankingTestScores()...))
+			ranks := collectRanking(t, s, tt.start, tt.limit)
+
+			if len(ranks) != len(tt.wantUsers) {
+				t.Fatalf("got %d ranks, want %d", len(ranks), len(tt.wantUsers))
+			}
+			for i, rank := range ranks {
+				if rank.UserID != tt.wantUsers[i] || rank.Rank != tt.wantRanks[i] {
+					t.Errorf("ranks[%d] = user %d rank %d, want user %d rank %d", i, rank.UserID, rank.Rank, tt.wantUsers[i], tt.wantRanks[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetRankingRejectsInvalidCursor(t *testing.T) {
+	s := NewGameService(newMemoryGameRepository())
+	for _, cursor := range []string{"not-base64!", "e30"} {
+		if _, err := s.GetRanking(0, cursor, 10); err != ErrInvalidCursor {
+			t.Errorf("GetRanking(%q) error = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
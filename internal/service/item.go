@@ -0,0 +1,31 @@
+package service
+
+// UserItemResponse はユーザーが所持するアイテムを表す構造体です。
+// Exp は育成素材として1個消費したときに得られる経験値で、育成素材でないアイテムは 0 です。
+type UserItemResponse struct {
+	ItemID   int64  `json:"itemID"`
+	Name     string `json:"name"`
+	Quantity int64  `json:"quantity"`
+	Exp      int64  `json:"exp"`
+}
+
+// ListItems は指定されたユーザーが所持するアイテムの一覧を取得します。
+// 重複したキャラクターを変換したアイテムなどが含まれます。
+func (s *characterService) ListItems(userID int64) ([]UserItemResponse, error) {
+	userItems, err := s.repo.GetUserItems(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]UserItemResponse, 0, len(userItems))
+	for _, item := range userItems {
+		items = append(items, UserItemResponse{
+			ItemID:   item.ItemID,
+			Name:     item.Name,
+			Quantity: item.Quantity,
+			Exp:      item.Exp,
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"my-go-project/internal/model"
+	"my-go-project/internal/repository"
+)
+
+const (
+	// MaxTeamMembers は1つのチームに編成できるキャラクターの最大数です。
+	MaxTeamMembers = 5
+	// MaxTeams は1人のユーザーが作成できるチームの最大数です。
+	MaxTeams = 10
+	// MaxTeamNameLength はチーム名の最大文字数です。
+	MaxTeamNameLength = 32
+)
+
+// TeamService はチーム編成関連のビジネスロジックを定義するインターフェースです。
+type TeamService interface {
+	ListTeams(userID int64) ([]TeamResponse, error)
+	CreateTeam(userID int64, req TeamRequest) (*TeamResponse, error)
+	UpdateTeam(userID, teamID int64, req TeamRequest) (*TeamResponse, error)
+	DeleteTeam(userID, teamID int64) error
+}
+
+// teamService は TeamService インターフェースを実装する構造体です。
+type teamService struct {
+	repo repository.TeamRepository
+}
+
+// NewTeamService は新しい TeamService を生成します。
+func NewTeamService(repo repository.TeamRepository) TeamService {
+	return &teamService{repo}
+}
+
+// TeamRequest はチームの作成・更新内容を表す構造体です。
+// UserCharacterIDs はスロット1から順に編成する所持キャラクターIDです。
+type TeamRequest struct {
+	Name             string  `json:"name"`
+	UserCharacterIDs []int64 `json:"userCharacterIDs"`
+}
+
+// TeamResponse はチームを表す構造体です。Members はスロット順の編成キャラクターです。
+type TeamResponse struct {
+	TeamID    int64                   `json:"teamID"`
+	Name      string                  `json:"name"`
+	Members   []UserCharacterResponse `json:"members"`
+	UpdatedAt time.Time               `json:"updatedAt"`
+}
+
+// ListTeams は指定されたユーザーのチームの一覧を取得します。
+func (s *teamService) ListTeams(userID int64) ([]TeamResponse, error) {
+	teams, err := s.repo.GetTeams(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, team := range teams {
+		for _, member := range team.Members {
+			ids = append(ids, member.UserCharacterID)
+		}
+	}
+	owned, err := s.repo.GetUserCharactersByIDs(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]TeamResponse, 0, len(teams))
+	for _, team := range teams {
+		responses = append(responses, teamResponse(team, owned))
+	}
+	return responses, nil
+}
+
+// CreateTeam は指定されたユーザーのチームを作成します。
+// チーム数が MaxTeams に達している場合は ErrTeamLimit を返します。
+func (s *teamService) CreateTeam(userID int64, req TeamRequest) (*TeamResponse, error) {
+	name, err := validateTeam(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *TeamResponse
+	err = s.repo.Transaction(func(repo repository.TeamRepository) error {
+		teams, err := repo.GetTeams(userID)
+		if err != nil {
+			return err
+		}
+		if len(teams) >= MaxTeams {
+			return ErrTeamLimit
+		}
+
+		owned, err := teamCharacters(repo, userID, req.UserCharacterIDs)
+		if err != nil {
+			return err
+		}
+		teamID, err := repo.CreateTeam(userID, name)
+		if err != nil {
+			return err
+		}
+		if err := repo.SaveTeamMembers(teamID, req.UserCharacterIDs); err != nil {
+			return err
+		}
+
+		team, err := repo.GetTeam(userID, teamID)
+		if err != nil {
+			return err
+		}
+		r := teamResponse(*team, owned)
+		response = &r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// UpdateTeam は指定されたユーザーのチームの名前と編成を置き換えます。
+// チームが存在しないか、他のユーザーのチームの場合は ErrTeamNotFound を返します。
+func (s *teamService) UpdateTeam(userID, teamID int64, req TeamRequest) (*TeamResponse, error) {
+	name, err := validateTeam(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *TeamResponse
+	err = s.repo.Transaction(func(repo repository.TeamRepository) error {
+		team, err := repo.GetTeam(userID, teamID)
+		if err != nil {
+			return err
+		}
+		if team == nil {
+			return ErrTeamNotFound
+		}
+
+		owned, err := teamCharacters(repo, userID, req.UserCharacterIDs)
+		if err != nil {
+			return err
+		}
+		if err := repo.UpdateTeamName(teamID, name); err != nil {
+			return err
+		}
+		if err := repo.SaveTeamMembers(teamID, req.UserCharacterIDs); err != nil {
+			return err
+		}
+
+		team, err = repo.GetTeam(userID, teamID)
+		if err != nil {
+			return err
+		}
+		r := teamResponse(*team, owned)
+		response = &r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// DeleteTeam は指定されたユーザーのチームを削除します。編成されていたキャラクターは削除されません。
+// チームが存在しないか、他のユーザーのチームの場合は ErrTeamNotFound を返します。
+func (s *teamService) DeleteTeam(userID, teamID int64) error {
+	deleted, err := s.repo.DeleteTeam(userID, teamID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrTeamNotFound
+	}
+	return nil
+}
+
+// validateTeam はチームの作成・更新内容を検証し、前後の空白を除いたチーム名を返します。
+// 同じ所持キャラクターの重複は teamCharacters で同じキャラクターの重複として検出します。
+func validateTeam(req TeamRequest) (string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > MaxTeamNameLength {
+		return "", ErrInvalidTeam
+	}
+	if len(req.UserCharacterIDs) > MaxTeamMembers {
+		return "", ErrInvalidTeam
+	}
+	for _, id := range req.UserCharacterIDs {
+		if id <= 0 {
+			return "", ErrInvalidTeam
+		}
+	}
+	return name, nil
+}
+
+// teamCharacters は編成する所持キャラクターがすべてユーザーの所持キャラクターであり、
+// 同じキャラクターが重複していないことを確認して、所持キャラクターを返します。
+func teamCharacters(repo repository.TeamRepository, userID int64, userCharacterIDs []int64) (map[int64]model.UserCharacter, error) {
+	owned, err := repo.GetUserCharactersByIDs(userID, userCharacterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	characters := make(map[int64]bool, len(userCharacterIDs))
+	for _, id := range userCharacterIDs {
+		uc, ok := owned[id]
+		if !ok {
+			return nil, ErrCharacterNotOwned
+		}
+		if characters[uc.CharacterID] {
+			return nil, ErrInvalidTeam
+		}
+		characters[uc.CharacterID] = true
+	}
+	return owned, nil
+}
+
+// teamResponse はチームをレスポンス用に変換します。owned には編成キャラクターが含まれている必要があります。
+func teamResponse(team model.Team, owned map[int64]model.UserCharacter) TeamResponse {
+	response := TeamResponse{
+		TeamID:    team.ID,
+		Name:      team.Name,
+		Members:   make([]UserCharacterResponse, 0, len(team.Members)),
+		UpdatedAt: team.UpdatedAt,
+	}
+	for _, member := range team.Members {
+		if uc, ok := owned[member.UserCharacterID]; ok {
+			response.Members = append(response.Members, userCharacterResponse(uc))
+		}
+	}
+	return response
+}
+
+// checkTeamAssigned は消費しようとした所持キャラクターがいずれかのチームに編成されている場合に ErrCharacterInTeam を返します。
+func checkTeamAssigned(repo repository.CharacterRepository, userCharacterIDs []int64) error {
+	assigned, err := repo.GetTeamAssignedCharacters(userCharacterIDs)
+	if err != nil {
+		return err
+	}
+	if len(assigned) > 0 {
+		return ErrCharacterInTeam
+	}
+	return nil
+}
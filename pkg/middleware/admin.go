@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// AdminMiddleware は x-admin-token ヘッダーが管理者トークンと一致するリクエストのみを次のハンドラーに渡すミドルウェアを生成します。
+func AdminMiddleware(adminToken string) func(http.Handler) http.Handler {
+	extract := HeaderTokenExtractor("x-admin-token")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := extract(r)
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TokenExtractor はリクエストから認証トークンを取り出す関数です。
+// トークンが見つからない場合は false を返します。
+type TokenExtractor func(r *http.Request) (string, bool)
+
+// HeaderTokenExtractor は指定されたヘッダーの値をそのままトークンとして取り出します。
+// API仕様書 (api-document.yaml) の x-token ヘッダーはこの形式です。
+func HeaderTokenExtractor(name string) TokenExtractor {
+	return func(r *http.Request) (string, bool) {
+		token := strings.TrimSpace(r.Header.Get(name))
+		return token, token != ""
+	}
+}
+
+// BearerTokenExtractor は Authorization: Bearer {token} 形式のヘッダーからトークンを取り出します。
+func BearerTokenExtractor() TokenExtractor {
+	return func(r *http.Request) (string, bool) {
+		parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			return "", false
+		}
+		token := strings.TrimSpace(parts[1])
+		return token, token != ""
+	}
+}
+
+// CookieTokenExtractor は指定された名前のクッキーからトークンを取り出します。
+// クッキーはブラウザが他のサイトからのリクエストにも自動で付与するため、CSRF を防ぐよう次のリクエストのクッキーは無視します。
+//   - Origin ヘッダーのホストがリクエスト先のホストと一致しないリクエスト
+//   - GET、HEAD、OPTIONS 以外で、Origin ヘッダーがないか Content-Type が application/json でないリクエスト
+//
+// application/json のリクエストは他のサイトのフォームからは送信できず、スクリプトからの送信は CORS のプリフライトで拒否されます。
+func CookieTokenExtractor(name string) TokenExtractor {
+	return func(r *http.Request) (string, bool) {
+		if !sameOriginRequest(r) {
+			return "", false
+		}
+		cookie, err := r.Cookie(name)
+		if err != nil || cookie.Value == "" {
+			return "", false
+		}
+		return cookie.Value, true
+	}
+}
+
+// sameOriginRequest はリクエストが同じホストのページから送信されたものとして扱えるかどうかを返します。
+// Origin ヘッダーのない GET などは、ブラウザ以外のクライアントからのリクエストとして受け付けます。
+func sameOriginRequest(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	safe := r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions
+	if origin == "" {
+		return safe
+	}
+	if !safe {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			return false
+		}
+	}
+
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && u.Host == r.Host
+}
+
+// ChainTokenExtractors は複数の TokenExtractor を順番に試し、最初に見つかったトークンを返します。
+func ChainTokenExtractors(extractors ...TokenExtractor) TokenExtractor {
+	return func(r *http.Request) (string, bool) {
+		for _, extract := range extractors {
+			if token, ok := extract(r); ok {
+				return token, true
+			}
+		}
+		return "", false
+	}
+}
+
+// DefaultTokenExtractor は x-token ヘッダー、Bearer トークンの順にトークンを探します。
+var DefaultTokenExtractor = ChainTokenExtractors(
+	HeaderTokenExtractor("x-token"),
+	BearerTokenExtractor(),
+)
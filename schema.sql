@@ -1,16 +1,54 @@
 -- users テーブルの作成
+-- coin はガチャの実行に使用する通貨で、新規ユーザーには初期コインが付与されます。
 CREATE TABLE IF NOT EXISTS users (
     id INT AUTO_INCREMENT PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
+    coin BIGINT NOT NULL DEFAULT 3000,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB;
+
+-- items テーブルの作成
+-- 重複したキャラクターの変換先となる欠片や、育成素材などのアイテムです。
+-- exp は育成素材として1個消費したときに得られる経験値で、0 のアイテムは育成素材として使用できません。
+CREATE TABLE IF NOT EXISTS items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    exp BIGINT NOT NULL DEFAULT 0,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 ) ENGINE=InnoDB;
 
 -- characters テーブルの作成
+-- duplicate_mode は所持済みのキャラクターを再度獲得したときの扱いです。
+--   keep: 所持キャラクターを追加する
+--   item: duplicate_item_id のアイテムを duplicate_item_quantity 個に変換する
+--   limit_break: 最初に獲得した所持キャラクターの限界突破レベルを max_limit_break まで上げ、上限に達した後はアイテムに変換する
+-- idx_characters_rarity は /character/list のレアリティによる絞り込みに使用します。
 CREATE TABLE IF NOT EXISTS characters (
     id INT AUTO_INCREMENT PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
     rarity INT NOT NULL,
+    duplicate_mode VARCHAR(16) NOT NULL DEFAULT 'keep',
+    duplicate_item_id INT NULL,
+    duplicate_item_quantity INT NOT NULL DEFAULT 0,
+    max_limit_break INT NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_characters_rarity (rarity),
+    FOREIGN KEY (duplicate_item_id) REFERENCES items(id)
+) ENGINE=InnoDB;
+
+-- gachas テーブルの作成
+-- cost は1回あたりの消費コインです。start_at / end_at が NULL の場合は、その側の期限がないことを表します。
+-- step_loop はステップアップガチャの場合のみ使用し、最終ステップの後にステップ1へ戻るかどうかを表します。
+CREATE TABLE IF NOT EXISTS gachas (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    cost BIGINT NOT NULL DEFAULT 0,
+    start_at DATETIME NULL,
+    end_at DATETIME NULL,
+    step_loop BOOLEAN NOT NULL DEFAULT FALSE,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 ) ENGINE=InnoDB;
@@ -18,35 +56,448 @@ CREATE TABLE IF NOT EXISTS characters (
 -- gacha_probabilities テーブルの作成
 CREATE TABLE IF NOT EXISTS gacha_probabilities (
     id INT AUTO_INCREMENT PRIMARY KEY,
+    gacha_id INT NOT NULL,
     character_id INT NOT NULL,
     probability FLOAT NOT NULL,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id),
     FOREIGN KEY (character_id) REFERENCES characters(id)
 ) ENGINE=InnoDB;
 
 -- user_characters テーブルの作成
+-- limit_break は重複したキャラクターにより限界突破した回数です。
+-- exp は育成で獲得した累計経験値で、level は exp とレアリティのレベル曲線(level_curves)から決まるレベルです。
+-- locked のキャラクターは育成の素材や売却に使用できません。favorite はプレイヤーが目印に使用するフラグです。
+-- /character/list の獲得日時順のページングと、キャラクターIDによる絞り込み・所持判定のためのインデックスを持ちます。
 CREATE TABLE IF NOT EXISTS user_characters (
     id INT AUTO_INCREMENT PRIMARY KEY,
     user_id INT NOT NULL,
     character_id INT NOT NULL,
-    acquired_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    limit_break INT NOT NULL DEFAULT 0,
+    level INT NOT NULL DEFAULT 1,
+    exp BIGINT NOT NULL DEFAULT 0,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    favorite BOOLEAN NOT NULL DEFAULT FALSE,
+    acquired_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_characters_user_acquired_at (user_id, acquired_at, id),
+    INDEX idx_user_characters_user_character (user_id, character_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (character_id) REFERENCES characters(id)
+) ENGINE=InnoDB;
+
+-- level_curves テーブルの作成
+-- レアリティごとに、各レベルに到達するために必要な累計経験値を定義します。レベル1は 0 で、定義された最大のレベルがレベル上限です。
+CREATE TABLE IF NOT EXISTS level_curves (
+    rarity INT NOT NULL,
+    level INT NOT NULL,
+    required_exp BIGINT NOT NULL,
+    PRIMARY KEY (rarity, level)
+) ENGINE=InnoDB;
+
+-- feed_exps テーブルの作成
+-- 所持キャラクターを育成素材として消費したときに得られる経験値を、消費したキャラクターのレアリティごとに定義します。
+CREATE TABLE IF NOT EXISTS feed_exps (
+    rarity INT PRIMARY KEY,
+    exp BIGINT NOT NULL
+) ENGINE=InnoDB;
+
+-- sell_rewards テーブルの作成
+-- 所持キャラクターを売却したときに付与するコインとアイテムを、売却したキャラクターのレアリティごとに定義します。
+-- item_id が NULL の場合、アイテムは付与しません。
+CREATE TABLE IF NOT EXISTS sell_rewards (
+    rarity INT PRIMARY KEY,
+    coin BIGINT NOT NULL DEFAULT 0,
+    item_id INT NULL,
+    item_quantity BIGINT NOT NULL DEFAULT 0,
+    FOREIGN KEY (item_id) REFERENCES items(id)
+) ENGINE=InnoDB;
+
+-- teams テーブルの作成
+-- ユーザーが所持キャラクターを編成したチームのプリセットです。
+CREATE TABLE IF NOT EXISTS teams (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_teams_user_id (user_id, id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB;
+
+-- team_members テーブルの作成
+-- チームのスロットごとの編成キャラクターです。同じ所持キャラクターを1つのチームに重複して編成することはできません。
+-- 編成中のキャラクターは売却や育成の素材に使用できませんが、所持キャラクターが削除された場合はスロットも削除されます。
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id INT NOT NULL,
+    slot INT NOT NULL,
+    user_character_id INT NOT NULL,
+    PRIMARY KEY (team_id, slot),
+    UNIQUE KEY uk_team_members_user_character (team_id, user_character_id),
+    INDEX idx_team_members_user_character_id (user_character_id),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_character_id) REFERENCES user_characters(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- user_collections テーブルの作成
+-- ユーザーが一度でも獲得したキャラクター(図鑑)です。所持キャラクターを売却や育成の素材で失っても削除しません。
+CREATE TABLE IF NOT EXISTS user_collections (
+    user_id INT NOT NULL,
+    character_id INT NOT NULL,
+    first_acquired_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, character_id),
     FOREIGN KEY (user_id) REFERENCES users(id),
     FOREIGN KEY (character_id) REFERENCES characters(id)
 ) ENGINE=InnoDB;
 
+-- collection_rewards テーブルの作成
+-- 図鑑の達成率が percent 以上になったときに1回だけ付与する報酬です。
+-- rarity が 0 の場合はすべてのキャラクター、それ以外はそのレアリティのキャラクターの達成率で判定します。
+CREATE TABLE IF NOT EXISTS collection_rewards (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    rarity INT NOT NULL DEFAULT 0,
+    percent INT NOT NULL,
+    coin BIGINT NOT NULL DEFAULT 0,
+    item_id INT NULL,
+    item_quantity BIGINT NOT NULL DEFAULT 0,
+    FOREIGN KEY (item_id) REFERENCES items(id)
+) ENGINE=InnoDB;
+
+-- user_collection_rewards テーブルの作成
+-- ユーザーに付与済みの図鑑の達成報酬です。
+CREATE TABLE IF NOT EXISTS user_collection_rewards (
+    user_id INT NOT NULL,
+    reward_id INT NOT NULL,
+    granted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, reward_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (reward_id) REFERENCES collection_rewards(id)
+) ENGINE=InnoDB;
+
+-- game_scores テーブルの作成
+-- /game/finish 1回ごとのスコアと、付与したコインの記録です。
+CREATE TABLE IF NOT EXISTS game_scores (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    score BIGINT NOT NULL,
+    coin BIGINT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_game_scores_user_id (user_id, id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB;
+
+-- user_high_scores テーブルの作成
+-- ユーザーごとの最高スコアで、/ranking/list の順位に使用します。achieved_at は最高スコアを初めて達成した日時で、同じスコアの並び順に使用します。
+-- idx_user_high_scores_ranking はランキングの並び順どおりのインデックスで、カーソルの位置からページを取得する際に使用します。
+CREATE TABLE IF NOT EXISTS user_high_scores (
+    user_id INT PRIMARY KEY,
+    score BIGINT NOT NULL,
+    achieved_at DATETIME NOT NULL,
+    INDEX idx_user_high_scores_ranking (score DESC, achieved_at, user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB;
+
+-- user_items テーブルの作成
+CREATE TABLE IF NOT EXISTS user_items (
+    user_id INT NOT NULL,
+    item_id INT NOT NULL,
+    quantity BIGINT NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, item_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (item_id) REFERENCES items(id)
+) ENGINE=InnoDB;
+
+-- gacha_guarantees テーブルの作成
+-- 1回のリクエストで draw_count 回以上引く場合、draw_count 回ごとの最後の1枠は min_rarity 以上から抽選されます。
+CREATE TABLE IF NOT EXISTS gacha_guarantees (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    gacha_id INT NOT NULL,
+    draw_count INT NOT NULL,
+    min_rarity INT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id)
+) ENGINE=InnoDB;
+
+-- gacha_pity_settings テーブルの作成
+-- hard_pity 回目で対象レアリティ以上が確定し、soft_pity_start 回を超えると 1 回ごとに soft_pity_step ずつ排出率が上がります。
+CREATE TABLE IF NOT EXISTS gacha_pity_settings (
+    gacha_id INT NOT NULL,
+    rarity INT NOT NULL,
+    hard_pity INT NOT NULL DEFAULT 0,
+    soft_pity_start INT NOT NULL DEFAULT 0,
+    soft_pity_step FLOAT NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (gacha_id, rarity),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id)
+) ENGINE=InnoDB;
+
+-- user_gacha_pities テーブルの作成
+CREATE TABLE IF NOT EXISTS user_gacha_pities (
+    user_id INT NOT NULL,
+    gacha_id INT NOT NULL,
+    rarity INT NOT NULL,
+    count INT NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, gacha_id, rarity),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id)
+) ENGINE=InnoDB;
+
+-- gacha_steps テーブルの作成
+-- 行が存在するガチャはステップアップガチャで、各ステップは times 回を cost コインで引きます。
+-- min_rarity はステップの最後の1枠の確定レアリティ(0 は確定なし)で、
+-- ステップ中は boost_rarity 以上のキャラクターの重みが boost_multiplier 倍になります。
+CREATE TABLE IF NOT EXISTS gacha_steps (
+    gacha_id INT NOT NULL,
+    step INT NOT NULL,
+    times INT NOT NULL,
+    cost BIGINT NOT NULL DEFAULT 0,
+    min_rarity INT NOT NULL DEFAULT 0,
+    boost_rarity INT NOT NULL DEFAULT 0,
+    boost_multiplier FLOAT NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (gacha_id, step),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id)
+) ENGINE=InnoDB;
+
+-- user_gacha_steps テーブルの作成
+-- current_step は次に引くステップで、completed はループしないステップアップガチャを引き終えたことを表します。
+CREATE TABLE IF NOT EXISTS user_gacha_steps (
+    user_id INT NOT NULL,
+    gacha_id INT NOT NULL,
+    current_step INT NOT NULL DEFAULT 1,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, gacha_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id)
+) ENGINE=InnoDB;
+
+-- gacha_rate_ups テーブルの作成
+-- rarity のキャラクターを引いたとき、share の割合でピックアップキャラクター(gacha_rate_up_characters)が選ばれるよう重みを調整します。
+-- guarantee が TRUE の場合、ピックアップ以外を引く(すり抜ける)と、次にそのレアリティを引いたときピックアップキャラクターが確定します。
+CREATE TABLE IF NOT EXISTS gacha_rate_ups (
+    gacha_id INT NOT NULL,
+    rarity INT NOT NULL,
+    share FLOAT NOT NULL,
+    guarantee BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (gacha_id, rarity),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id)
+) ENGINE=InnoDB;
+
+-- gacha_rate_up_characters テーブルの作成
+-- ピックアップキャラクターで、キャラクターのレアリティの gacha_rate_ups の設定が適用されます。
+CREATE TABLE IF NOT EXISTS gacha_rate_up_characters (
+    gacha_id INT NOT NULL,
+    character_id INT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (gacha_id, character_id),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id),
+    FOREIGN KEY (character_id) REFERENCES characters(id)
+) ENGINE=InnoDB;
+
+-- user_gacha_rate_ups テーブルの作成
+-- guaranteed は、次にそのレアリティを引いたときピックアップキャラクターが確定しているかどうかを表します。
+CREATE TABLE IF NOT EXISTS user_gacha_rate_ups (
+    user_id INT NOT NULL,
+    gacha_id INT NOT NULL,
+    rarity INT NOT NULL,
+    guaranteed BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, gacha_id, rarity),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id)
+) ENGINE=InnoDB;
+
+-- gacha_box_items テーブルの作成
+-- 行が存在するガチャは BOX ガチャで、ユーザーごとの BOX に各キャラクターが quantity 個ずつ入った状態から非復元抽出で引きます。
+-- featured のキャラクター(目玉賞品)を引くと、BOX が空になる前でもリセットできます。
+CREATE TABLE IF NOT EXISTS gacha_box_items (
+    gacha_id INT NOT NULL,
+    character_id INT NOT NULL,
+    quantity INT NOT NULL,
+    featured BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (gacha_id, character_id),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id),
+    FOREIGN KEY (character_id) REFERENCES characters(id)
+) ENGINE=InnoDB;
+
+-- user_gacha_boxes テーブルの作成
+-- box_number はリセットのたびに増える現在の BOX の番号で、featured_drawn は現在の BOX で目玉賞品を引いたかどうかを表します。
+CREATE TABLE IF NOT EXISTS user_gacha_boxes (
+    user_id INT NOT NULL,
+    gacha_id INT NOT NULL,
+    box_number INT NOT NULL DEFAULT 1,
+    featured_drawn BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, gacha_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id)
+) ENGINE=InnoDB;
+
+-- user_gacha_box_draws テーブルの作成
+-- 現在の BOX からキャラクターごとに引いた数で、リセット時に削除されます。
+CREATE TABLE IF NOT EXISTS user_gacha_box_draws (
+    user_id INT NOT NULL,
+    gacha_id INT NOT NULL,
+    character_id INT NOT NULL,
+    drawn INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, gacha_id, character_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id),
+    FOREIGN KEY (character_id) REFERENCES characters(id)
+) ENGINE=InnoDB;
+
+-- gacha_draw_requests テーブルの作成
+-- Idempotency-Key ヘッダー付きの /gacha/draw の結果を保存し、再送時に同じ結果を返すために使用します。
+CREATE TABLE IF NOT EXISTS gacha_draw_requests (
+    user_id INT NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    gacha_id INT NOT NULL,
+    times INT NOT NULL,
+    response JSON NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, idempotency_key),
+    INDEX idx_gacha_draw_requests_created_at (created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB;
+
+-- gacha_draw_logs テーブルの作成
+-- /gacha/draw 1回ごとの監査ログです。seed は抽選に使用した乱数のシード、results は付与した user_characters を含む結果です。
+CREATE TABLE IF NOT EXISTS gacha_draw_logs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    batch_id CHAR(32) NOT NULL,
+    user_id INT NOT NULL,
+    gacha_id INT NOT NULL,
+    times INT NOT NULL,
+    seed BIGINT NOT NULL,
+    results JSON NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_gacha_draw_logs_batch_id (batch_id),
+    INDEX idx_gacha_draw_logs_user_id (user_id, id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (gacha_id) REFERENCES gachas(id)
+) ENGINE=InnoDB;
+
+-- アイテムの初期データ
+INSERT INTO items (id, name, exp) VALUES
+(1, 'Memory Shard', 0),       -- 重複したキャラクターの変換先
+(2, 'Training Manual', 100),  -- 育成素材
+(3, 'Master Manual', 1000);
+
+-- レベル曲線の初期データ (レアリティが高いほどレベル上限が高く、レベル n に必要な累計経験値は 50 * n * (n - 1))
+INSERT INTO level_curves (rarity, level, required_exp)
+WITH RECURSIVE levels (level) AS (
+    SELECT 1
+    UNION ALL
+    SELECT level + 1 FROM levels WHERE level < 60
+)
+SELECT r.rarity, l.level, 50 * l.level * (l.level - 1)
+FROM (
+    SELECT 1 AS rarity, 20 AS max_level
+    UNION ALL SELECT 2, 30
+    UNION ALL SELECT 3, 40
+    UNION ALL SELECT 4, 50
+    UNION ALL SELECT 5, 60
+) r
+JOIN levels l ON l.level <= r.max_level;
+
+-- 所持キャラクターを消費したときの経験値の初期データ
+INSERT INTO feed_exps (rarity, exp) VALUES
+(1, 100),
+(2, 300),
+(3, 1000),
+(4, 3000),
+(5, 10000);
+
+-- 売却報酬の初期データ (高レアリティは育成素材も付与)
+INSERT INTO sell_rewards (rarity, coin, item_id, item_quantity) VALUES
+(1, 10, NULL, 0),
+(2, 30, NULL, 0),
+(3, 100, 2, 1),   -- Training Manual 1個
+(4, 300, 2, 5),
+(5, 1000, 3, 1);  -- Master Manual 1個
+
+-- 図鑑の達成報酬の初期データ
+INSERT INTO collection_rewards (rarity, percent, coin, item_id, item_quantity) VALUES
+(0, 50, 500, NULL, 0),    -- 全キャラクターの半分を獲得
+(0, 100, 3000, 3, 1),     -- 全キャラクターを獲得 (Master Manual 1個)
+(5, 100, 1000, NULL, 0);  -- レアリティ5をすべて獲得
+
 -- キャラクターの初期データ
-INSERT INTO characters (name, rarity) VALUES
-('Warrior', 1),
-('Mage', 2),
-('Archer', 3),
-('Knight', 4),
-('Dragon', 5);
+INSERT INTO characters (name, rarity, duplicate_mode, duplicate_item_id, duplicate_item_quantity, max_limit_break) VALUES
+('Warrior', 1, 'keep', NULL, 0, 0),          -- 重複しても所持キャラクターを追加
+('Mage', 2, 'keep', NULL, 0, 0),
+('Archer', 3, 'item', 1, 5, 0),              -- 重複は Memory Shard 5個に変換
+('Knight', 4, 'limit_break', 1, 10, 4),      -- 4回まで限界突破、以降は Memory Shard 10個
+('Dragon', 5, 'limit_break', 1, 50, 5),      -- 5回まで限界突破、以降は Memory Shard 50個
+('Phoenix', 5, 'limit_break', 1, 50, 5);
+
+-- ガチャの初期データ
+INSERT INTO gachas (id, name, cost, start_at, end_at, step_loop) VALUES
+(1, 'Standard', 100, NULL, NULL, FALSE),                                      -- 常設ガチャ
+(2, 'Dragon Festival', 150, '2024-01-01 00:00:00', '2030-01-01 00:00:00', FALSE), -- 期間限定ガチャ
+(3, 'Step-up Summon', 0, NULL, NULL, TRUE),                                    -- ステップアップガチャ (5ステップでループ)
+(4, 'Event Box', 50, NULL, NULL, FALSE);                                       -- BOX ガチャ
 
 -- ガチャ確率の初期データ
-INSERT INTO gacha_probabilities (character_id, probability) VALUES
-(1, 0.4),  -- Warrior
-(2, 0.3),  -- Mage
-(3, 0.2),  -- Archer
-(4, 0.08), -- Knight
-(5, 0.02); -- Dragon
+INSERT INTO gacha_probabilities (gacha_id, character_id, probability) VALUES
+(1, 1, 0.4),  -- Standard: Warrior
+(1, 2, 0.3),  -- Standard: Mage
+(1, 3, 0.2),  -- Standard: Archer
+(1, 4, 0.08), -- Standard: Knight
+(1, 5, 0.02), -- Standard: Dragon
+(2, 1, 0.35), -- Dragon Festival: Warrior
+(2, 2, 0.3),  -- Dragon Festival: Mage
+(2, 3, 0.2),  -- Dragon Festival: Archer
+(2, 4, 0.1),  -- Dragon Festival: Knight
+(2, 5, 0.025), -- Dragon Festival: Dragon
+(2, 6, 0.025), -- Dragon Festival: Phoenix
+(3, 1, 0.4),  -- Step-up Summon: Warrior
+(3, 2, 0.3),  -- Step-up Summon: Mage
+(3, 3, 0.2),  -- Step-up Summon: Archer
+(3, 4, 0.08), -- Step-up Summon: Knight
+(3, 5, 0.02); -- Step-up Summon: Dragon
+
+-- 確定枠の初期データ
+INSERT INTO gacha_guarantees (gacha_id, draw_count, min_rarity) VALUES
+(1, 10, 3), -- Standard: 10連の最後の1枠は Archer 以上確定
+(2, 10, 4); -- Dragon Festival: 10連の最後の1枠は Knight 以上確定
+
+-- 天井設定の初期データ
+INSERT INTO gacha_pity_settings (gacha_id, rarity, hard_pity, soft_pity_start, soft_pity_step) VALUES
+(1, 5, 80, 60, 0.05), -- Standard: Dragon は60回を超えると排出率上昇、80回で確定
+(1, 4, 10, 0, 0),     -- Standard: Knight以上は10回で確定
+(2, 5, 60, 40, 0.05), -- Dragon Festival: レアリティ5は40回を超えると排出率上昇、60回で確定
+(2, 4, 10, 0, 0);     -- Dragon Festival: Knight以上は10回で確定
+
+-- ステップアップガチャの初期データ
+INSERT INTO gacha_steps (gacha_id, step, times, cost, min_rarity, boost_rarity, boost_multiplier) VALUES
+(3, 1, 10, 500, 0, 0, 1),  -- Step-up Summon: ステップ1は半額
+(3, 2, 10, 800, 3, 0, 1),  -- Step-up Summon: ステップ2は Archer 以上1枠確定
+(3, 3, 10, 1000, 4, 0, 1), -- Step-up Summon: ステップ3は Knight 以上1枠確定
+(3, 4, 10, 1000, 4, 5, 2), -- Step-up Summon: ステップ4は Dragon の排出率2倍
+(3, 5, 10, 1000, 5, 0, 1); -- Step-up Summon: ステップ5は Dragon 1枠確定
+
+-- BOX ガチャの初期データ
+INSERT INTO gacha_box_items (gacha_id, character_id, quantity, featured) VALUES
+(4, 1, 20, FALSE), -- Event Box: Warrior
+(4, 2, 15, FALSE), -- Event Box: Mage
+(4, 3, 10, FALSE), -- Event Box: Archer
+(4, 4, 4, FALSE),  -- Event Box: Knight
+(4, 5, 1, TRUE);   -- Event Box: Dragon (目玉賞品)
+
+-- ピックアップの初期データ
+INSERT INTO gacha_rate_ups (gacha_id, rarity, share, guarantee) VALUES
+(2, 5, 0.5, TRUE); -- Dragon Festival: レアリティ5の半分はピックアップ、すり抜けたら次回確定
+
+INSERT INTO gacha_rate_up_characters (gacha_id, character_id) VALUES
+(2, 5); -- Dragon Festival: Dragon をピックアップ
@@ -28,14 +28,189 @@ get_response_after=$(curl -s -X GET -H "x-token: $token" http://localhost:8080/u
 echo "Response from /user/get (after update):"
 echo $get_response_after
 
+# 提供割合取得 (/gacha/rates)
+echo "Getting gacha rates..."
+rates_response=$(curl -s -X GET http://localhost:8080/gacha/rates)
+echo "Response from /gacha/rates:"
+echo $rates_response
+
+# キャラクターマスター取得 (/character/master)
+echo "Getting character master..."
+master_response=$(curl -s -X GET http://localhost:8080/character/master)
+echo "Response from /character/master:"
+echo $master_response
+
+# ETag によるキャッシュの検証 (変更がなければ 304)
+master_etag=$(curl -s -o /dev/null -D - http://localhost:8080/character/master | grep -i '^etag:' | cut -d' ' -f2 | tr -d '\r')
+master_status=$(curl -s -o /dev/null -w "%{http_code}" -H "If-None-Match: $master_etag" http://localhost:8080/character/master)
+echo "Status from /character/master with If-None-Match: $master_status"
+
+# 開催中ガチャ一覧取得 (/gacha/list)
+echo "Listing open gachas..."
+gacha_list_response=$(curl -s -X GET -H "x-token: $token" http://localhost:8080/gacha/list)
+echo "Response from /gacha/list:"
+echo $gacha_list_response
+
 # ガチャ実行 (/gacha/draw)
 echo "Drawing gacha 3 times..."
 gacha_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d '{"times": 3}' http://localhost:8080/gacha/draw)
 echo "Response from /gacha/draw:"
 echo $gacha_response
 
+# 期間限定ガチャ実行 (/gacha/draw)
+echo "Drawing limited gacha 3 times..."
+limited_gacha_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d '{"gachaID": 2, "times": 3}' http://localhost:8080/gacha/draw)
+echo "Response from /gacha/draw (gachaID=2):"
+echo $limited_gacha_response
+
+# ステップアップガチャ実行 (/gacha/draw)
+echo "Drawing step-up gacha (step 1, 10 times)..."
+stepup_gacha_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d '{"gachaID": 3, "times": 10}' http://localhost:8080/gacha/draw)
+echo "Response from /gacha/draw (gachaID=3):"
+echo $stepup_gacha_response
+
+# BOX ガチャ実行 (/gacha/draw)
+echo "Drawing box gacha 5 times..."
+box_gacha_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d '{"gachaID": 4, "times": 5}' http://localhost:8080/gacha/draw)
+echo "Response from /gacha/draw (gachaID=4):"
+echo $box_gacha_response
+
+# BOX の中身取得 (/gacha/box)
+echo "Getting box contents..."
+box_response=$(curl -s -X GET -H "x-token: $token" "http://localhost:8080/gacha/box?gachaID=4")
+echo "Response from /gacha/box:"
+echo $box_response
+
+# 所持アイテム一覧取得 (/item/list)
+echo "Listing items..."
+item_list_response=$(curl -s -X GET -H "x-token: $token" http://localhost:8080/item/list)
+echo "Response from /item/list:"
+echo $item_list_response
+
+# 天井カウンター取得 (/gacha/pity)
+echo "Getting gacha pity counters..."
+pity_response=$(curl -s -X GET -H "x-token: $token" http://localhost:8080/gacha/pity)
+echo "Response from /gacha/pity:"
+echo $pity_response
+
+# ガチャ実行履歴取得 (/gacha/history)
+echo "Getting gacha history..."
+history_response=$(curl -s -X GET -H "x-token: $token" "http://localhost:8080/gacha/history?limit=5")
+echo "Response from /gacha/history:"
+echo $history_response
+
 # ユーザー所持キャラクター一覧取得 (/character/list)
 echo "Listing user characters..."
 character_list_response=$(curl -s -X GET -H "x-token: $token" http://localhost:8080/character/list)
 echo "Response from /character/list:"
 echo $character_list_response
+
+# キャラクター育成 (/character/feed)
+# 一覧の先頭のキャラクターに、2番目のキャラクターを消費して経験値を与える
+user_character_ids=$(echo $character_list_response | grep -o '"userCharacterID":[0-9]*' | cut -d: -f2)
+feed_target=$(echo "$user_character_ids" | sed -n 1p)
+feed_material=$(echo "$user_character_ids" | sed -n 2p)
+if [ -n "$feed_target" ] && [ -n "$feed_material" ]; then
+  echo "Feeding character $feed_material to $feed_target..."
+  feed_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d "{\"userCharacterID\": $feed_target, \"userCharacterIDs\": [$feed_material]}" http://localhost:8080/character/feed)
+  echo "Response from /character/feed:"
+  echo $feed_response
+fi
+
+# キャラクターのロック・お気に入り (/character/flags)
+# 育成したキャラクターをロックしてお気に入りにする
+if [ -n "$feed_target" ]; then
+  echo "Locking character $feed_target..."
+  flags_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d "{\"userCharacterIDs\": [$feed_target], \"locked\": true, \"favorite\": true}" http://localhost:8080/character/flags)
+  echo "Response from /character/flags:"
+  echo $flags_response
+
+  # ロックしたキャラクターは売却できない (400)
+  locked_sell_status=$(curl -s -o /dev/null -w "%{http_code}" -X POST -H "Content-Type: application/json" -H "x-token: $token" -d "{\"userCharacterIDs\": [$feed_target]}" http://localhost:8080/character/sell)
+  echo "Status from /character/sell with a locked character: $locked_sell_status"
+
+  favorite_list_response=$(curl -s -X GET -H "x-token: $token" "http://localhost:8080/character/list?favorite=true")
+  echo "Response from /character/list?favorite=true:"
+  echo $favorite_list_response
+fi
+
+# キャラクター売却 (/character/sell)
+# 一覧の3番目のキャラクターを売却する
+sell_target=$(echo "$user_character_ids" | sed -n 3p)
+if [ -n "$sell_target" ]; then
+  echo "Selling character $sell_target..."
+  sell_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d "{\"userCharacterIDs\": [$sell_target]}" http://localhost:8080/character/sell)
+  echo "Response from /character/sell:"
+  echo $sell_response
+fi
+
+# チーム作成 (/team/create)
+if [ -n "$feed_target" ]; then
+  echo "Creating a team..."
+  team_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d "{\"name\": \"Main\", \"userCharacterIDs\": [$feed_target]}" http://localhost:8080/team/create)
+  echo "Response from /team/create:"
+  echo $team_response
+  team_id=$(echo $team_response | grep -o '"teamID":[0-9]*' | cut -d: -f2)
+
+  # チーム一覧取得 (/team/list)
+  team_list_response=$(curl -s -X GET -H "x-token: $token" http://localhost:8080/team/list)
+  echo "Response from /team/list:"
+  echo $team_list_response
+
+  # チーム更新 (/team/update)
+  team_update_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d "{\"teamID\": $team_id, \"name\": \"Main Team\", \"userCharacterIDs\": [$feed_target]}" http://localhost:8080/team/update)
+  echo "Response from /team/update:"
+  echo $team_update_response
+
+  # チーム削除 (/team/delete)
+  team_delete_status=$(curl -s -o /dev/null -w "%{http_code}" -X POST -H "Content-Type: application/json" -H "x-token: $token" -d "{\"teamID\": $team_id}" http://localhost:8080/team/delete)
+  echo "Status from /team/delete: $team_delete_status"
+fi
+
+# キャラクター図鑑取得 (/character/collection)
+echo "Getting character collection..."
+collection_response=$(curl -s -X GET -H "x-token: $token" http://localhost:8080/character/collection)
+echo "Response from /character/collection:"
+echo $collection_response
+
+# 図鑑の達成報酬受け取り (/character/collection/claim)
+echo "Claiming collection rewards..."
+claim_response=$(curl -s -X POST -H "x-token: $token" http://localhost:8080/character/collection/claim)
+echo "Response from /character/collection/claim:"
+echo $claim_response
+
+# 並び替え・絞り込み・ページング (/character/list)
+echo "Listing user characters sorted by rarity (2 per page)..."
+character_page_response=$(curl -s -X GET -H "x-token: $token" "http://localhost:8080/character/list?sort=rarity&order=desc&limit=2")
+echo "Response from /character/list (page 1):"
+echo $character_page_response
+next_cursor=$(echo $character_page_response | sed -n 's/.*"nextCursor":"\([^"]*\)".*/\1/p')
+if [ -n "$next_cursor" ]; then
+  character_page_response=$(curl -s -X GET -H "x-token: $token" "http://localhost:8080/character/list?sort=rarity&order=desc&limit=2&cursor=$next_cursor")
+  echo "Response from /character/list (page 2):"
+  echo $character_page_response
+fi
+
+# ゲーム終了 (/game/finish)
+echo "Finishing a game with score 1234..."
+finish_response=$(curl -s -X POST -H "Content-Type: application/json" -H "x-token: $token" -d '{"score": 1234}' http://localhost:8080/game/finish)
+echo "Response from /game/finish:"
+echo $finish_response
+
+# ランキング取得 (/ranking/list)
+echo "Getting ranking..."
+ranking_response=$(curl -s -X GET -H "x-token: $token" "http://localhost:8080/ranking/list?limit=10")
+echo "Response from /ranking/list:"
+echo $ranking_response
+ranking_cursor=$(echo $ranking_response | sed -n 's/.*"nextCursor":"\([^"]*\)".*/\1/p')
+if [ -n "$ranking_cursor" ]; then
+  ranking_response=$(curl -s -X GET -H "x-token: $token" "http://localhost:8080/ranking/list?limit=10&cursor=$ranking_cursor")
+  echo "Response from /ranking/list (page 2):"
+  echo $ranking_response
+fi
+
+# 指定した位置からのランキング取得 (/ranking/list?start=)
+echo "Getting ranking from position 2..."
+ranking_response=$(curl -s -X GET -H "x-token: $token" "http://localhost:8080/ranking/list?start=2&limit=10")
+echo "Response from /ranking/list (start=2):"
+echo $ranking_response